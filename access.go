@@ -0,0 +1,40 @@
+package serial
+
+import (
+	"errors"
+	"syscall"
+)
+
+// AccessMode selects the directions a port is opened for (see OpenMode).
+type AccessMode int
+
+// Access modes.
+const (
+	ReadWrite AccessMode = iota // Read and write (default)
+	ReadOnly                    // Read only, writes fail with ErrReadOnly
+	WriteOnly                   // Write only, reads fail with ErrWriteOnly
+)
+
+// ErrReadOnly is returned when writing to a port opened with ReadOnly.
+var ErrReadOnly = errors.New("port opened read-only")
+
+// ErrWriteOnly is returned when reading from a port opened with WriteOnly.
+var ErrWriteOnly = errors.New("port opened write-only")
+
+// OpenMode opens serial like Open, with the given access mode.
+// Useful to sniff a line without write permission on the device, or to
+// drive a transmit-only port.
+func OpenMode(path string, mode AccessMode, opts ...Option) (*Serial, error) {
+	return openAccess(path, 0, mode, opts...)
+}
+
+// oflag returns the open(2) access flag for mode.
+func (mode AccessMode) oflag() int {
+	switch mode {
+	case ReadOnly:
+		return syscall.O_RDONLY
+	case WriteOnly:
+		return syscall.O_WRONLY
+	}
+	return syscall.O_RDWR
+}
@@ -0,0 +1,53 @@
+package serial
+
+import (
+	"strings"
+	"time"
+)
+
+// ATError is returned by ATCommand when the modem answers with an error final result code.
+type ATError struct {
+	Result string // Final result code (Ex. "ERROR", "+CME ERROR: 10")
+}
+
+func (e *ATError) Error() string {
+	return "AT command failed: " + e.Result
+}
+
+// atFinal reports whether line is an AT final result code and, in that case,
+// the error it stands for (nil for success codes).
+func atFinal(line string) (bool, error) {
+	switch {
+	case line == "OK", strings.HasPrefix(line, "CONNECT"):
+		return true, nil
+	case line == "ERROR", line == "NO CARRIER", line == "BUSY", line == "NO ANSWER", line == "NO DIALTONE",
+		strings.HasPrefix(line, "+CME ERROR"), strings.HasPrefix(line, "+CMS ERROR"):
+		return true, &ATError{Result: line}
+	}
+	return false, nil
+}
+
+// ATCommand sends AT command cmd (CR terminated) and reads response lines until a final
+// result code (OK, ERROR, +CME ERROR...) is received or timeout expires.
+// It returns the response lines, excluding command echo, empty lines and the final result code.
+// Error final result codes are returned as *ATError.
+func (s *Serial) ATCommand(cmd string, timeout time.Duration) (resp []string, err error) {
+	defer s.endRead(s.beginRead(timeout))
+	if _, err = s.WriteString(cmd + "\r"); err != nil {
+		return nil, err
+	}
+	for {
+		var line string
+		if line, err = s.ReadLine(); err != nil {
+			return resp, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line == cmd {
+			continue
+		}
+		if final, err := atFinal(line); final {
+			return resp, err
+		}
+		resp = append(resp, line)
+	}
+}
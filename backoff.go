@@ -0,0 +1,64 @@
+package serial
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// BackoffPolicy configures OpenWithBackoff retries.
+type BackoffPolicy struct {
+	Initial    time.Duration // First retry delay (default 100ms)
+	Max        time.Duration // Max retry delay (0 = no limit)
+	Multiplier float64       // Delay growth factor between retries (default 2)
+	Deadline   time.Duration // Total time allowed for retries (0 = retry forever)
+}
+
+// transientOpenError reports whether open error err may go away by itself
+// (device still enumerating, busy or not ready).
+func transientOpenError(err error) bool {
+	return errors.Is(err, ErrDeviceGone) ||
+		errors.Is(err, syscall.ENOENT) ||
+		errors.Is(err, syscall.EBUSY) ||
+		errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.EIO)
+}
+
+// OpenWithBackoff opens serial like Open, retrying with exponential backoff while
+// the error is transient (missing device node, device not present, busy or not ready).
+// Permanent errors (Ex. permission denied) are returned at once.
+// When the policy deadline expires the last error is returned.
+func OpenWithBackoff(path string, policy BackoffPolicy, opts ...Option) (*Serial, error) {
+	delay := policy.Initial
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+	mult := policy.Multiplier
+	if mult < 1 {
+		mult = 2
+	}
+	var end time.Time
+	if policy.Deadline > 0 {
+		end = time.Now().Add(policy.Deadline)
+	}
+	for {
+		s, err := Open(path, opts...)
+		if err == nil || !transientOpenError(err) {
+			return s, err
+		}
+		if !end.IsZero() {
+			left := time.Until(end)
+			if left <= 0 {
+				return nil, err
+			}
+			if delay > left {
+				delay = left
+			}
+		}
+		time.Sleep(delay)
+		delay = time.Duration(float64(delay) * mult)
+		if policy.Max > 0 && delay > policy.Max {
+			delay = policy.Max
+		}
+	}
+}
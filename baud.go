@@ -0,0 +1,67 @@
+package serial
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrBaudNotDetected is returned by AutoBaud when no candidate rate is confirmed.
+var ErrBaudNotDetected = errors.New("baud rate not detected")
+
+// DetectBaudMismatch reads up to sample bytes within timeout and heuristically reports
+// whether the current speed looks wrong for the incoming data.
+// The port's framing/parity error counters are checked when the driver provides them,
+// together with the ratio of non printable bytes in the sample.
+// It returns ErrTimeout if no byte arrives within timeout.
+func (s *Serial) DetectBaudMismatch(sample int, timeout time.Duration) (bool, error) {
+	if sample <= 0 {
+		return false, errors.New("invalid sample size")
+	}
+	var before, after icounter
+	counters := s.getICount(&before) == nil
+
+	defer s.endRead(s.beginRead(timeout))
+	buf := make([]byte, sample)
+	n, err := s.readFull(buf)
+	if n == 0 {
+		return false, err
+	}
+	if err != nil && err != ErrTimeout {
+		return false, err
+	}
+
+	bad := 0
+	if counters && s.getICount(&after) == nil {
+		bad = int(after.frame-before.frame) + int(after.parity-before.parity)
+	}
+	invalid := 0
+	for _, c := range buf[:n] {
+		if (c < 0x20 || c > 0x7e) && c != '\r' && c != '\n' && c != '\t' {
+			invalid++
+		}
+	}
+	// More than 5% framing/parity errors or 25% of garbage is not a healthy link.
+	return bad*20 > n || invalid*4 > n, nil
+}
+
+// AutoBaud tries candidate rates in order: for each one the port is set to it,
+// input is flushed and probe is called with reads bounded by perRate (reads return
+// ErrTimeout when it expires). The first rate confirmed by probe is returned and left set.
+// ErrBaudNotDetected is returned if no rate is confirmed.
+func (s *Serial) AutoBaud(candidates []int, probe func(*Serial) bool, perRate time.Duration) (int, error) {
+	for _, rate := range candidates {
+		if err := s.SetSpeed(rate); err != nil {
+			return 0, err
+		}
+		if err := s.Flush(FLUSH_I); err != nil {
+			return 0, err
+		}
+		op := s.beginRead(perRate)
+		ok := probe(s)
+		s.endRead(op)
+		if ok {
+			return rate, nil
+		}
+	}
+	return 0, ErrBaudNotDetected
+}
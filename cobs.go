@@ -0,0 +1,139 @@
+package serial
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrCOBSCorrupt is returned when decoding a malformed COBS frame.
+var ErrCOBSCorrupt = errors.New("corrupt COBS frame")
+
+// ErrFrameTooLong is returned when a received frame exceeds the maximum length.
+var ErrFrameTooLong = errors.New("frame too long")
+
+// COBSEncode encodes p using Consistent Overhead Byte Stuffing.
+// The result contains no zero bytes and doesn't include the frame delimiter.
+func COBSEncode(p []byte) []byte {
+	out := make([]byte, 1, len(p)+len(p)/254+2)
+	ci, code := 0, byte(1)
+	for i, b := range p {
+		if b != 0 {
+			out = append(out, b)
+			code++
+		}
+		if b == 0 || code == 0xff {
+			out[ci] = code
+			if b != 0 && i == len(p)-1 {
+				return out // A full block ending the data needs no empty block after it
+			}
+			ci, code = len(out), 1
+			out = append(out, 0)
+		}
+	}
+	out[ci] = code
+	return out
+}
+
+// COBSDecode decodes a COBS encoded frame (without delimiter).
+func COBSDecode(p []byte) ([]byte, error) {
+	out := make([]byte, 0, len(p))
+	for i := 0; i < len(p); {
+		code := int(p[i])
+		if code == 0 || i+code > len(p) {
+			return nil, ErrCOBSCorrupt
+		}
+		for _, b := range p[i+1 : i+code] {
+			if b == 0 {
+				return nil, ErrCOBSCorrupt
+			}
+		}
+		out = append(out, p[i+1:i+code]...)
+		i += code
+		if code < 0xff && i < len(p) {
+			out = append(out, 0)
+		}
+	}
+	return out, nil
+}
+
+// COBSWriter writes COBS encoded frames delimited by a zero byte.
+type COBSWriter struct {
+	w io.Writer
+}
+
+// NewCOBSWriter returns a COBSWriter writing to w (Ex. a *Serial).
+func NewCOBSWriter(w io.Writer) *COBSWriter {
+	return &COBSWriter{w: w}
+}
+
+// WriteFrame encodes p and writes it followed by the zero delimiter.
+func (c *COBSWriter) WriteFrame(p []byte) error {
+	b := append(COBSEncode(p), 0)
+	for len(b) > 0 {
+		n, err := c.w.Write(b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// COBSReader reads COBS encoded frames delimited by a zero byte.
+type COBSReader struct {
+	r   io.ByteReader
+	max int
+}
+
+// NewCOBSReader returns a COBSReader reading from r (Ex. a *Serial).
+// Encoded frames longer than max bytes are rejected with ErrFrameTooLong (0 = unlimited).
+func NewCOBSReader(r io.ByteReader, max int) *COBSReader {
+	return &COBSReader{r: r, max: max}
+}
+
+// ReadFrame reads bytes until the zero delimiter and returns the decoded frame.
+// Empty frames (consecutive delimiters) are skipped.
+// Frames too long are discarded up to the next delimiter before returning ErrFrameTooLong.
+func (c *COBSReader) ReadFrame() ([]byte, error) {
+	var buf []byte
+	tooLong := false
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != 0 {
+			if c.max > 0 && len(buf) >= c.max {
+				tooLong = true
+			} else {
+				buf = append(buf, b)
+			}
+			continue
+		}
+		if tooLong {
+			return nil, ErrFrameTooLong
+		}
+		if len(buf) > 0 {
+			return COBSDecode(buf)
+		}
+	}
+}
+
+// WriteCOBS writes pkt as a single COBS encoded frame followed by the zero delimiter.
+func (s *Serial) WriteCOBS(pkt []byte) error {
+	_, err := s.WriteFull(append(COBSEncode(pkt), 0))
+	return err
+}
+
+// ReadCOBS reads a COBS frame up to the zero delimiter and returns it decoded (see COBSReader).
+// Encoded frames longer than max bytes are rejected with ErrFrameTooLong (0 = unlimited),
+// malformed ones with ErrCOBSCorrupt.
+// The default read timeout bounds the whole frame rather than each read; an explicit
+// read deadline applies as usual.
+func (s *Serial) ReadCOBS(max int) ([]byte, error) {
+	s.dmu.Lock()
+	d := s.rto
+	s.dmu.Unlock()
+	defer s.endRead(s.beginRead(d))
+	return NewCOBSReader(s, max).ReadFrame()
+}
@@ -0,0 +1,156 @@
+package serial
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// seq returns bytes from to to, both included.
+func seq(from, to int) []byte {
+	b := make([]byte, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		b = append(b, byte(i))
+	}
+	return b
+}
+
+func cat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+var cobsTests = []struct {
+	dec, enc []byte
+}{
+	{[]byte{}, []byte{0x01}},
+	{[]byte{0x00}, []byte{0x01, 0x01}},
+	{[]byte{0x00, 0x00}, []byte{0x01, 0x01, 0x01}},
+	{[]byte{0x00, 0x11, 0x00}, []byte{0x01, 0x02, 0x11, 0x01}},
+	{[]byte{0x11, 0x22, 0x00, 0x33}, []byte{0x03, 0x11, 0x22, 0x02, 0x33}},
+	{[]byte{0x11, 0x22, 0x33, 0x44}, []byte{0x05, 0x11, 0x22, 0x33, 0x44}},
+	{[]byte{0x11, 0x00, 0x00, 0x00}, []byte{0x02, 0x11, 0x01, 0x01, 0x01}},
+	{seq(0x01, 0xfe), cat([]byte{0xff}, seq(0x01, 0xfe))},
+	{seq(0x00, 0xfe), cat([]byte{0x01, 0xff}, seq(0x01, 0xfe))},
+	{seq(0x01, 0xff), cat([]byte{0xff}, seq(0x01, 0xfe), []byte{0x02, 0xff})},
+	{cat(seq(0x02, 0xff), []byte{0x00}), cat([]byte{0xff}, seq(0x02, 0xff), []byte{0x01, 0x01})},
+	{cat(seq(0x03, 0xff), []byte{0x00, 0x01}), cat([]byte{0xfe}, seq(0x03, 0xff), []byte{0x02, 0x01})},
+}
+
+func TestCOBSEncode(t *testing.T) {
+	for _, tt := range cobsTests {
+		if got := COBSEncode(tt.dec); !bytes.Equal(got, tt.enc) {
+			t.Errorf("COBSEncode(% x) = % x, want % x", tt.dec, got, tt.enc)
+		}
+	}
+}
+
+func TestCOBSDecode(t *testing.T) {
+	for _, tt := range cobsTests {
+		got, err := COBSDecode(tt.enc)
+		if err != nil || !bytes.Equal(got, tt.dec) {
+			t.Errorf("COBSDecode(% x) = % x, %v, want % x", tt.enc, got, err, tt.dec)
+		}
+	}
+}
+
+func TestCOBSDecodeCorrupt(t *testing.T) {
+	for _, enc := range [][]byte{
+		{0x00},             // Zero code
+		{0x03, 0x11},       // Code past the end
+		{0x03, 0x11, 0x00}, // Zero inside a block
+		{0x02, 0x11, 0x00, 0x22},
+	} {
+		if got, err := COBSDecode(enc); err != ErrCOBSCorrupt {
+			t.Errorf("COBSDecode(% x) = % x, %v, want ErrCOBSCorrupt", enc, got, err)
+		}
+	}
+}
+
+func TestCOBSReaderWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCOBSWriter(&buf)
+	frames := [][]byte{{0x11, 0x00, 0x22}, seq(0x00, 0xff), {0x00}}
+	for _, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bytes.Count(buf.Bytes(), []byte{0}) != len(frames) {
+		t.Fatalf("want %d delimiters in % x", len(frames), buf.Bytes())
+	}
+	buf.WriteByte(0) // Empty frame, skipped
+	r := NewCOBSReader(&buf, 0)
+	for _, f := range frames {
+		got, err := r.ReadFrame()
+		if err != nil || !bytes.Equal(got, f) {
+			t.Fatalf("ReadFrame = % x, %v, want % x", got, err, f)
+		}
+	}
+}
+
+func TestCOBSReaderTooLong(t *testing.T) {
+	buf := bytes.NewBuffer(cat(COBSEncode(seq(0x01, 0x10)), []byte{0}, COBSEncode([]byte{0x42}), []byte{0}))
+	r := NewCOBSReader(buf, 8)
+	if _, err := r.ReadFrame(); err != ErrFrameTooLong {
+		t.Fatalf("ReadFrame error = %v, want ErrFrameTooLong", err)
+	}
+	if got, err := r.ReadFrame(); err != nil || !bytes.Equal(got, []byte{0x42}) {
+		t.Fatalf("ReadFrame after too long = % x, %v", got, err)
+	}
+}
+
+func TestReadWriteCOBS(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	frames := [][]byte{{0x00}, []byte("hello"), seq(0x00, 0xff)}
+	for _, f := range frames {
+		if err := a.WriteCOBS(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range frames {
+		got, err := b.ReadCOBS(0)
+		if err != nil || !bytes.Equal(got, f) {
+			t.Fatalf("ReadCOBS = % x, %v, want % x", got, err, f)
+		}
+	}
+}
+
+func TestReadCOBSErrors(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	a.Write([]byte{0x03, 0x11, 0x00}) // Stuffing code past the end
+	if _, err := b.ReadCOBS(0); err != ErrCOBSCorrupt {
+		t.Fatalf("corrupt frame error = %v, want ErrCOBSCorrupt", err)
+	}
+	a.WriteCOBS(seq(0x01, 0x20))
+	if _, err := b.ReadCOBS(8); err != ErrFrameTooLong {
+		t.Fatalf("long frame error = %v, want ErrFrameTooLong", err)
+	}
+}
+
+func TestReadCOBSTimeout(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(100 * time.Millisecond)
+	// Bytes keep arriving, but the frame is never completed: the timeout bounds
+	// the whole frame, not each read.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(30 * time.Millisecond):
+				a.Write([]byte{0x01})
+			}
+		}
+	}()
+	start := time.Now()
+	if _, err := b.ReadCOBS(0); err != ErrTimeout {
+		t.Fatalf("ReadCOBS error = %v, want ErrTimeout", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("ReadCOBS took %v", d)
+	}
+}
@@ -0,0 +1,250 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds serial settings applied in a single transaction by ApplyConfig.
+type Config struct {
+	Speed      int  // Baud rate (0 = unchanged)
+	Bits       int  // Data bits, 5 to 8 (0 = unchanged)
+	Parity     int  // PAR_NONE, PAR_EVEN, PAR_ODD, PAR_MARK or PAR_SPACE
+	StopBits   int  // 1, 2 or STOP_1_5 (0 = unchanged)
+	HwFlowCtrl bool // RTS/CTS flow control
+	SwFlowCtrl bool // XON/XOFF flow control
+	Local      bool // Ignore modem control lines (false = unchanged, on by default after Open)
+
+	ReadTimeout time.Duration // Default read timeout, see SetDefaultReadTimeout (0 = unchanged)
+}
+
+// DefaultConfig returns the settings Open applies: DefaultSpeed, DefaultBits, no parity,
+// 1 stop bit, no flow control and local mode. Tweak it and pass it to OpenWithConfig.
+func DefaultConfig() Config {
+	return Config{
+		Speed:    DefaultSpeed,
+		Bits:     DefaultBits,
+		Parity:   PAR_NONE,
+		StopBits: 1,
+		Local:    true,
+	}
+}
+
+var parityChars = map[int]byte{
+	PAR_NONE:  'N',
+	PAR_EVEN:  'E',
+	PAR_ODD:   'O',
+	PAR_MARK:  'M',
+	PAR_SPACE: 'S',
+}
+
+// ParseConfig parses settings in the conventional stty/minicom notation:
+//   "<speed> [<bits><parity><stop>] [rtscts|xonxoff|none]"
+// Ex. "115200 8N1", "9600 7E2 rtscts". Frame defaults to 8N1 when omitted.
+func ParseConfig(str string) (Config, error) {
+	cfg := Config{Bits: DefaultBits, Parity: PAR_NONE, StopBits: 1}
+	fields := strings.Fields(str)
+	if len(fields) == 0 {
+		return cfg, errors.New("empty serial config")
+	}
+	speed, err := strconv.Atoi(fields[0])
+	if err != nil || speed <= 0 {
+		return cfg, fmt.Errorf("invalid speed %q", fields[0])
+	}
+	cfg.Speed = speed
+	fields = fields[1:]
+	if len(fields) > 0 && len(fields[0]) >= 3 && fields[0][0] >= '0' && fields[0][0] <= '9' {
+		if err := parseFrame(fields[0], &cfg); err != nil {
+			return cfg, err
+		}
+		fields = fields[1:]
+	}
+	for _, f := range fields {
+		switch strings.ToLower(f) {
+		case "rtscts", "crtscts", "hw":
+			cfg.HwFlowCtrl = true
+		case "xonxoff", "ixon", "sw":
+			cfg.SwFlowCtrl = true
+		case "none":
+		default:
+			return cfg, fmt.Errorf("unknown serial config option %q", f)
+		}
+	}
+	return cfg, nil
+}
+
+// parseFrame parses frame notation like "8N1" or "5N1.5".
+func parseFrame(f string, cfg *Config) error {
+	bits := int(f[0] - '0')
+	if bits < 5 || bits > 8 {
+		return fmt.Errorf("invalid data bits in %q", f)
+	}
+	var stop int
+	switch f[2:] {
+	case "1":
+		stop = 1
+	case "2":
+		stop = 2
+	case "1.5":
+		stop = STOP_1_5
+	default:
+		return fmt.Errorf("invalid stop bits in %q", f)
+	}
+	if stop == STOP_1_5 && bits != 5 {
+		return fmt.Errorf("1.5 stop bits require 5 data bits in %q", f)
+	}
+	parity := -1
+	for k, v := range parityChars {
+		if v == f[1] || v+'a'-'A' == f[1] {
+			parity = k
+		}
+	}
+	if parity < 0 {
+		return fmt.Errorf("invalid parity in %q", f)
+	}
+	cfg.Bits, cfg.Parity, cfg.StopBits = bits, parity, stop
+	return nil
+}
+
+// String formats config in the notation accepted by ParseConfig (Ex. "9600 8N1 rtscts").
+func (c Config) String() string {
+	p, ok := parityChars[c.Parity]
+	if !ok {
+		p = '?'
+	}
+	str := fmt.Sprintf("%d %d%c%s", c.Speed, c.Bits, p, stopBitsString(c.StopBits))
+	if c.HwFlowCtrl {
+		str += " rtscts"
+	}
+	if c.SwFlowCtrl {
+		str += " xonxoff"
+	}
+	return str
+}
+
+// termConfig applies cfg to t.
+func termConfig(t *Termios, cfg Config) error {
+	if cfg.Speed != 0 {
+		if err := termSpeed(t, cfg.Speed); err != nil {
+			return fmt.Errorf("speed %d: %w", cfg.Speed, err)
+		}
+	}
+	if cfg.Bits != 0 {
+		if err := termBits(t, cfg.Bits); err != nil {
+			return fmt.Errorf("bits %d: %w", cfg.Bits, err)
+		}
+	}
+	if err := termParity(t, cfg.Parity); err != nil {
+		return fmt.Errorf("parity %d: %w", cfg.Parity, err)
+	}
+	if cfg.StopBits != 0 {
+		if err := termStopBits(t, cfg.StopBits); err != nil {
+			return fmt.Errorf("stop bits %d: %w", cfg.StopBits, err)
+		}
+	}
+	if cfg.ReadTimeout < 0 {
+		return fmt.Errorf("read timeout %v: negative timeout", cfg.ReadTimeout)
+	}
+	termHwFlowCtrl(t, cfg.HwFlowCtrl)
+	termSwFlowCtrl(t, cfg.SwFlowCtrl)
+	if cfg.Local {
+		termLocal(t, true)
+	}
+	return nil
+}
+
+// ApplyConfig applies all settings in cfg with a single attribute change.
+// If any setting is invalid an error is returned and serial is left untouched.
+func (s *Serial) ApplyConfig(cfg Config) error {
+	if cfg.HwFlowCtrl && cfg.SwFlowCtrl && s.strict.Load() {
+		return s.wrapErr("ApplyConfig", strictError("hardware and software flow control enabled together"))
+	}
+	defer s.dropInput()
+	if err := s.updateAttr(func(t *Termios) error { return termConfig(t, cfg) }); err != nil {
+		return s.wrapErr("ApplyConfig", err)
+	}
+	if cfg.ReadTimeout != 0 {
+		s.SetDefaultReadTimeout(cfg.ReadTimeout)
+	}
+	return nil
+}
+
+// WithConfig applies cfg at open time (see ApplyConfig).
+func WithConfig(cfg Config) Option {
+	return func(s *Serial) error {
+		return s.ApplyConfig(cfg)
+	}
+}
+
+// OpenWithConfig opens serial with default params and then applies cfg with a single
+// attribute change. Zero fields keep the defaults (see Open).
+// If cfg is invalid the port is closed and a descriptive error is returned.
+func OpenWithConfig(path string, cfg Config) (*Serial, error) {
+	return Open(path, WithConfig(cfg))
+}
+
+// SetMode applies a classic mode string "baud,bits,parity,stop" (Ex. "9600,8,N,1")
+// with a single attribute change. Parity is N (none), E (even), O (odd), M (mark) or S (space),
+// stop is 1, 2 or 1.5 (5 data bits only).
+// Flow control settings are left unchanged.
+func (s *Serial) SetMode(mode string) error {
+	f := strings.Split(mode, ",")
+	if len(f) != 4 {
+		return s.wrapErr("SetMode", fmt.Errorf("invalid mode %q: want baud,bits,parity,stop", mode))
+	}
+	for i := range f {
+		f[i] = strings.TrimSpace(f[i])
+	}
+	speed, err := strconv.Atoi(f[0])
+	if err != nil || speed <= 0 {
+		return s.wrapErr("SetMode", fmt.Errorf("invalid mode %q: bad baud rate %q", mode, f[0]))
+	}
+	if len(f[1]) != 1 || len(f[2]) != 1 || f[3] == "" {
+		return s.wrapErr("SetMode", fmt.Errorf("invalid mode %q: bad frame", mode))
+	}
+	cfg := Config{Speed: speed}
+	if err := parseFrame(f[1]+f[2]+f[3], &cfg); err != nil {
+		return s.wrapErr("SetMode", fmt.Errorf("invalid mode %q: %w", mode, err))
+	}
+	defer s.dropInput()
+	return s.wrapErr("SetMode", s.updateAttr(func(t *Termios) error {
+		if err := termSpeed(t, cfg.Speed); err != nil {
+			return err
+		}
+		if err := termBits(t, cfg.Bits); err != nil {
+			return err
+		}
+		if err := termParity(t, cfg.Parity); err != nil {
+			return err
+		}
+		return termStopBits(t, cfg.StopBits)
+	}))
+}
+
+// Mode returns current settings as a mode string accepted by SetMode (Ex. "9600,8,N,1").
+func (s *Serial) Mode() (string, error) {
+	speed, err := s.speed()
+	if err != nil {
+		return "", err
+	}
+	cf, err := s.controlFlags()
+	if err != nil {
+		return "", err
+	}
+	p, ok := parityChars[cf.Parity]
+	if !ok {
+		p = '?'
+	}
+	return fmt.Sprintf("%d,%d,%c,%s", speed, cf.DataBits, p, stopBitsString(cf.StopBits)), nil
+}
+
+// stopBitsString formats stop bits, "1.5" for STOP_1_5.
+func stopBitsString(stop int) string {
+	if stop == STOP_1_5 {
+		return "1.5"
+	}
+	return strconv.Itoa(stop)
+}
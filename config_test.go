@@ -0,0 +1,61 @@
+package serial
+
+import "testing"
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		in  string
+		cfg Config
+	}{
+		{"9600", Config{Speed: 9600, Bits: 8, Parity: PAR_NONE, StopBits: 1}},
+		{"115200 8N1", Config{Speed: 115200, Bits: 8, Parity: PAR_NONE, StopBits: 1}},
+		{"9600 7E2 rtscts", Config{Speed: 9600, Bits: 7, Parity: PAR_EVEN, StopBits: 2, HwFlowCtrl: true}},
+		{"19200 8o1 xonxoff", Config{Speed: 19200, Bits: 8, Parity: PAR_ODD, StopBits: 1, SwFlowCtrl: true}},
+		{"1200 5M1.5", Config{Speed: 1200, Bits: 5, Parity: PAR_MARK, StopBits: STOP_1_5}},
+		{"4800 6S1 none", Config{Speed: 4800, Bits: 6, Parity: PAR_SPACE, StopBits: 1}},
+		{" 9600  HW  sw ", Config{Speed: 9600, Bits: 8, Parity: PAR_NONE, StopBits: 1, HwFlowCtrl: true, SwFlowCtrl: true}},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseConfig(tt.in)
+		if err != nil || cfg != tt.cfg {
+			t.Errorf("ParseConfig(%q) = %+v, %v, want %+v", tt.in, cfg, err, tt.cfg)
+		}
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"fast",
+		"-9600",
+		"0 8N1",
+		"9600 9N1",
+		"9600 4N1",
+		"9600 8X1",
+		"9600 8N3",
+		"9600 8N1.5",
+		"9600 8N1 dtrdsr",
+		"9600 8N",
+	} {
+		if _, err := ParseConfig(in); err == nil {
+			t.Errorf("ParseConfig(%q) succeeded", in)
+		}
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	for _, str := range []string{
+		"9600 8N1",
+		"115200 7E2 rtscts",
+		"1200 5M1.5 xonxoff",
+		"4800 6S1 rtscts xonxoff",
+	} {
+		cfg, err := ParseConfig(str)
+		if err != nil {
+			t.Fatalf("ParseConfig(%q): %v", str, err)
+		}
+		if got := cfg.String(); got != str {
+			t.Errorf("ParseConfig(%q).String() = %q", str, got)
+		}
+	}
+}
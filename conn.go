@@ -0,0 +1,31 @@
+package serial
+
+import "net"
+
+// Serial can be used wherever a net.Conn is expected.
+var _ net.Conn = (*Serial)(nil)
+
+// Addr is the net.Addr of a serial port.
+type Addr struct {
+	Path string // Device path
+}
+
+// Network returns "serial".
+func (a *Addr) Network() string {
+	return "serial"
+}
+
+// String returns the device path.
+func (a *Addr) String() string {
+	return a.Path
+}
+
+// LocalAddr returns the serial device path as net.Addr.
+func (s *Serial) LocalAddr() net.Addr {
+	return &Addr{Path: s.Name()}
+}
+
+// RemoteAddr returns the serial device path as net.Addr, a serial link has no remote address.
+func (s *Serial) RemoteAddr() net.Addr {
+	return &Addr{Path: s.Name()}
+}
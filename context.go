@@ -0,0 +1,49 @@
+package serial
+
+import "context"
+
+// withContext runs read operation fn, canceling it when ctx is done.
+// Reads canceled by ctx return ctx.Err() instead of ErrTimeout.
+func (s *Serial) withContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	op := s.beginRead(0)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.cancelRead(op)
+		case <-done:
+		}
+	}()
+	err := fn()
+	close(done)
+	s.endRead(op)
+	if err == ErrTimeout && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// ReadContext reads like Read, returning ctx.Err() promptly when ctx is done first.
+// Cancellation only affects this call, next reads use the deadlines in effect.
+func (s *Serial) ReadContext(ctx context.Context, b []byte) (n int, err error) {
+	err = s.withContext(ctx, func() error {
+		var rerr error
+		n, rerr = s.Read(b)
+		return rerr
+	})
+	return n, err
+}
+
+// ReadLineContext reads a line like ReadLine, returning ctx.Err() promptly when ctx is done first.
+// As with ReadLine, the partial line read before cancellation is kept pending.
+func (s *Serial) ReadLineContext(ctx context.Context) (line string, err error) {
+	err = s.withContext(ctx, func() error {
+		var rerr error
+		line, rerr = s.ReadLine()
+		return rerr
+	})
+	return line, err
+}
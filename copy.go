@@ -0,0 +1,59 @@
+package serial
+
+import "io"
+
+// copyBufSize is the chunk size used by ReadFrom and WriteTo.
+const copyBufSize = 4096
+
+// ReadFrom writes data read from r until EOF or error (io.ReaderFrom), so io.Copy
+// to serial moves data in big chunks. Each chunk is written under the write deadline
+// in effect (explicit deadline or default write timeout).
+func (s *Serial) ReadFrom(r io.Reader) (int64, error) {
+	buf := make([]byte, copyBufSize)
+	var total int64
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			s.wmu.Lock()
+			nw, err := s.writeFull(buf[:n])
+			s.wmu.Unlock()
+			total += int64(nw)
+			if err != nil {
+				return total, err
+			}
+		}
+		if rerr == io.EOF {
+			return total, nil
+		}
+		if rerr != nil {
+			return total, rerr
+		}
+	}
+}
+
+// WriteTo writes data read from serial to w (io.WriterTo) until an error occurs.
+// Read timeout (explicit deadline or default read timeout) ends the copy without error,
+// so io.Copy from serial can be bounded.
+func (s *Serial) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, copyBufSize)
+	var total int64
+	for {
+		n, rerr := s.read(buf)
+		if n > 0 {
+			nw, err := w.Write(buf[:n])
+			total += int64(nw)
+			if err == nil && nw < n {
+				err = io.ErrShortWrite
+			}
+			if err != nil {
+				return total, err
+			}
+		}
+		if rerr == ErrTimeout || rerr == io.EOF {
+			return total, nil
+		}
+		if rerr != nil {
+			return total, rerr
+		}
+	}
+}
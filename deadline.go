@@ -0,0 +1,195 @@
+package serial
+
+import "time"
+
+// readOp is an operation made of several reads (ATCommand, background readers...)
+// bounded by its own deadline, that can be canceled from other goroutine.
+type readOp struct {
+	deadline time.Time
+	canceled bool
+	endless  bool // Ignores the explicit read deadline, see beginBackgroundRead
+	prev     *readOp
+}
+
+// beginRead starts a read operation with timeout d (no timeout if d <= 0).
+// Operations can be nested, the earliest deadline applies. Must be paired with endRead.
+func (s *Serial) beginRead(d time.Duration) *readOp {
+	op := &readOp{}
+	if d > 0 {
+		op.deadline = time.Now().Add(d)
+	}
+	s.pushRead(op)
+	return op
+}
+
+// pushRead makes op the innermost read operation.
+func (s *Serial) pushRead(op *readOp) {
+	s.dmu.Lock()
+	op.prev = s.rop
+	s.rop = op
+	s.dmu.Unlock()
+}
+
+// beginBackgroundRead starts a read operation with no timeout for a background reader loop.
+// The explicit read deadline (SetReadDeadline) doesn't apply to its reads: once past it would
+// make every read fail at once, spinning the loop. The default read timeout still applies.
+func (s *Serial) beginBackgroundRead() *readOp {
+	op := &readOp{endless: true}
+	s.pushRead(op)
+	return op
+}
+
+// endRead ends read operation op, unlinking it wherever it is: overlapping operations
+// (Ex. a background reader and an ATCommand) may end out of order.
+func (s *Serial) endRead(op *readOp) {
+	s.dmu.Lock()
+	for p := &s.rop; *p != nil; p = &(*p).prev {
+		if *p == op {
+			*p = op.prev
+			break
+		}
+	}
+	s.dmu.Unlock()
+}
+
+// cancelRead cancels read operation op, pending and subsequent reads
+// return ErrTimeout until the operation ends.
+func (s *Serial) cancelRead(op *readOp) error {
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	op.canceled = true
+	s.frdl = time.Unix(1, 0)
+	return s.file().SetReadDeadline(s.frdl)
+}
+
+// readDeadline returns the read deadline in effect for next read, s.dmu must be held.
+func (s *Serial) readDeadline() time.Time {
+	dl := s.rdl
+	for op := s.rop; op != nil; op = op.prev {
+		if op.endless {
+			dl = time.Time{}
+			break
+		}
+	}
+	if dl.IsZero() && s.rto > 0 {
+		dl = time.Now().Add(s.rto)
+	}
+	dl = s.idleDeadline(dl)
+	for op := s.rop; op != nil; op = op.prev {
+		if op.canceled {
+			return time.Unix(1, 0)
+		}
+		if !op.deadline.IsZero() && (dl.IsZero() || op.deadline.Before(dl)) {
+			dl = op.deadline
+		}
+	}
+	return dl
+}
+
+// armRead sets on the file the read deadline in effect for next read.
+func (s *Serial) armRead() error {
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	dl := s.readDeadline()
+	if dl.Equal(s.frdl) {
+		return nil
+	}
+	if err := s.file().SetReadDeadline(dl); err != nil {
+		return err
+	}
+	s.frdl = dl
+	return nil
+}
+
+// writeDeadline returns the write deadline in effect for next write, s.dmu must be held.
+func (s *Serial) writeDeadline() time.Time {
+	dl := s.wdl
+	if dl.IsZero() && s.wto > 0 {
+		dl = time.Now().Add(s.wto)
+	}
+	return s.idleDeadline(dl)
+}
+
+// armWrite sets on the file the write deadline in effect for next write.
+func (s *Serial) armWrite() error {
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	dl := s.writeDeadline()
+	if dl.Equal(s.fwdl) {
+		return nil
+	}
+	if err := s.file().SetWriteDeadline(dl); err != nil {
+		return err
+	}
+	s.fwdl = dl
+	return nil
+}
+
+// WithReadTimeout sets the default read timeout (see SetDefaultReadTimeout).
+func WithReadTimeout(d time.Duration) Option {
+	return func(s *Serial) error {
+		s.SetDefaultReadTimeout(d)
+		return nil
+	}
+}
+
+// WithWriteTimeout sets the default write timeout (see SetDefaultWriteTimeout).
+func WithWriteTimeout(d time.Duration) Option {
+	return func(s *Serial) error {
+		s.SetDefaultWriteTimeout(d)
+		return nil
+	}
+}
+
+// SetDefaultReadTimeout sets a timeout applied to each read (deadline now+d) while no explicit
+// read deadline is set. Zero disables the default timeout.
+func (s *Serial) SetDefaultReadTimeout(d time.Duration) {
+	s.dmu.Lock()
+	s.rto = d
+	s.dmu.Unlock()
+}
+
+// SetDefaultWriteTimeout sets a timeout applied to each write (deadline now+d) while no explicit
+// write deadline is set. Zero disables the default timeout.
+func (s *Serial) SetDefaultWriteTimeout(d time.Duration) {
+	s.dmu.Lock()
+	s.wto = d
+	s.dmu.Unlock()
+}
+
+// SetReadTimeout sets a timeout measured from the start of each read, like SetDefaultReadTimeout.
+// Zero or negative d means no timeout (reads block until data arrives).
+// An explicit read deadline (SetReadDeadline) takes precedence while set.
+func (s *Serial) SetReadTimeout(d time.Duration) {
+	s.SetDefaultReadTimeout(d)
+}
+
+// SetWriteTimeout sets a timeout measured from the start of each write, like SetDefaultWriteTimeout.
+// Zero or negative d means no timeout (writes block until done).
+// An explicit write deadline (SetWriteDeadline) takes precedence while set.
+func (s *Serial) SetWriteTimeout(d time.Duration) {
+	s.SetDefaultWriteTimeout(d)
+}
+
+// SetIdleTimeout aborts reads and writes after d without I/O activity: each read or write
+// syscall gets an idle deadline of now+d, and as a long operation (Ex. ReadLine) issues a new
+// read every time bytes arrive, a slow but steady link stays alive while a dead one times out
+// (ErrTimeout) after d of silence. Pauses between operations don't count.
+// It applies on top of other deadlines and timeouts (the earliest wins). Zero disables it.
+func (s *Serial) SetIdleTimeout(d time.Duration) {
+	s.idle.Store(int64(d))
+}
+
+// idleDeadline returns the earliest of deadline dl and the idle deadline for a read or
+// write about to start.
+func (s *Serial) idleDeadline(dl time.Time) time.Time {
+	d := s.idle.Load()
+	if d <= 0 {
+		return dl
+	}
+	idl := time.Now().Add(time.Duration(d))
+	if dl.IsZero() || idl.Before(dl) {
+		return idl
+	}
+	return dl
+}
@@ -0,0 +1,18 @@
+package serial
+
+import "os"
+
+// DupFile duplicates serial file descriptor (Ex. to hand it to a child process through
+// exec.Cmd.ExtraFiles, which makes it inheritable in the child only).
+// The duplicate has close on exec set, so other children don't inherit it by accident.
+// It shares the open port with serial: termios settings, modem control lines and
+// file status flags are common, so settings made on serial are seen by the child and
+// the descriptor is in non blocking mode (readers must handle EAGAIN).
+// Closing the duplicate doesn't close serial.
+func (s *Serial) DupFile() (*os.File, error) {
+	fd, err := dup(int(s.file().Fd()))
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), s.Name()), nil
+}
@@ -0,0 +1,38 @@
+package serial
+
+import "errors"
+
+// ErrEchoMismatch is returned by reads with Serial.SuppressEcho set when received data
+// doesn't match the echo of written bytes (collision or corrupted echo on the bus).
+// The echo still expected is forgotten and the bytes received from the mismatch on are
+// returned by next reads, so no real data is swallowed.
+var ErrEchoMismatch = errors.New("echo mismatch")
+
+// expectEcho records written bytes b as echo to be dropped from received data.
+func (s *Serial) expectEcho(b []byte) {
+	s.bmu.Lock()
+	s.echo = append(s.echo, b...)
+	s.bmu.Unlock()
+}
+
+// dropEcho removes expected echo from the start of received bytes b, compacting b.
+// It returns the number of bytes left in b or ErrEchoMismatch, in that case bytes from
+// the mismatch on are kept read ahead.
+func (s *Serial) dropEcho(b []byte) (int, error) {
+	s.bmu.Lock()
+	i := 0
+	for ; i < len(b) && len(s.echo) > 0; i++ {
+		if b[i] != s.echo[0] {
+			s.echo = nil
+			s.bmu.Unlock()
+			s.unread(b[i:])
+			return 0, ErrEchoMismatch
+		}
+		s.echo = s.echo[1:]
+	}
+	if len(s.echo) == 0 {
+		s.echo = nil
+	}
+	s.bmu.Unlock()
+	return copy(b, b[i:]), nil
+}
@@ -0,0 +1,69 @@
+package serial
+
+// SetInputErrorCheck enables (INPCK|PARMRK) or disables marking of bytes received with
+// parity or framing errors. While enabled the driver escapes the input stream:
+//   \377 \0 X   byte X received with error (a break is received as \377 \0 \0)
+//   \377 \377   literal \377 byte
+// Use ReadErrChecked to decode it; plain Read returns the escaped stream as is.
+// Parity errors are only detected with parity enabled (see SetParity).
+func (s *Serial) SetInputErrorCheck(on bool) error {
+	defer s.dropInput()
+	return s.wrapErr("SetInputErrorCheck", s.setInputCheck(on))
+}
+
+// ReadErrChecked reads like Read from a serial with input error check enabled
+// (see SetInputErrorCheck), decoding error marks. It returns the number of bytes
+// stored on b and the indices (into b) of the bytes received with parity or framing errors.
+// Incomplete escape sequences are kept until the rest of them arrives.
+func (s *Serial) ReadErrChecked(b []byte) (int, []int, error) {
+	if len(b) == 0 {
+		return 0, nil, nil
+	}
+	raw := make([]byte, len(b)+2)
+	have := 0
+	for {
+		n, err := s.Read(raw[have:])
+		have += n
+		out, bad, used := parmrkDecode(b, raw[:have])
+		if out > 0 || err != nil {
+			s.unread(raw[used:have])
+			return out, bad, err
+		}
+		have = copy(raw, raw[used:have])
+	}
+}
+
+// parmrkDecode decodes PARMRK escaped src into dst. It returns bytes stored on dst,
+// indices of error marked bytes and bytes consumed from src.
+// Decoding stops at dst full or at an incomplete trailing escape sequence.
+func parmrkDecode(dst, src []byte) (n int, bad []int, used int) {
+	for used < len(src) && n < len(dst) {
+		c := src[used]
+		if c != 0xff {
+			dst[n] = c
+			n++
+			used++
+			continue
+		}
+		if used+1 >= len(src) {
+			break
+		}
+		switch src[used+1] {
+		case 0xff:
+			dst[n] = 0xff
+			used += 2
+		case 0:
+			if used+2 >= len(src) {
+				return
+			}
+			dst[n] = src[used+2]
+			bad = append(bad, n)
+			used += 3
+		default: // Not an escape sequence, pass through
+			dst[n] = c
+			used++
+		}
+		n++
+	}
+	return
+}
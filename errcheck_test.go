@@ -0,0 +1,36 @@
+package serial
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParmrkDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  []byte
+		dst  int
+		out  []byte
+		bad  []int
+		used int
+	}{
+		{"plain", []byte("abc"), 8, []byte("abc"), nil, 3},
+		{"literal ff", []byte{'a', 0xff, 0xff, 'b'}, 8, []byte{'a', 0xff, 'b'}, nil, 4},
+		{"error mark", []byte{'a', 0xff, 0x00, 'x', 'b'}, 8, []byte("axb"), []int{1}, 5},
+		{"break", []byte{0xff, 0x00, 0x00}, 8, []byte{0x00}, []int{0}, 3},
+		{"pass through", []byte{0xff, 'a'}, 8, []byte{0xff, 'a'}, nil, 2},
+		{"trailing ff", []byte{'a', 0xff}, 8, []byte("a"), nil, 1},
+		{"trailing ff 00", []byte{'a', 0xff, 0x00}, 8, []byte("a"), nil, 1},
+		{"dst full", []byte{'a', 0xff, 0x00, 'x', 'b'}, 2, []byte("ax"), []int{1}, 4},
+		{"empty dst", []byte("abc"), 0, []byte{}, nil, 0},
+	}
+	for _, tt := range tests {
+		dst := make([]byte, tt.dst)
+		n, bad, used := parmrkDecode(dst, tt.src)
+		if !bytes.Equal(dst[:n], tt.out) || !reflect.DeepEqual(bad, tt.bad) || used != tt.used {
+			t.Errorf("%s: parmrkDecode(% x) = % x, %v, %d, want % x, %v, %d",
+				tt.name, tt.src, dst[:n], bad, used, tt.out, tt.bad, tt.used)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package serial
+
+// SerialError is returned by serial setters (SetSpeed, SetParity, SetAttr...), telling the
+// operation and port that failed. The cause is available through errors.Is and errors.As
+// (Ex. errors.Is(err, ErrParityUnsupported)).
+type SerialError struct {
+	Op   string // Operation (Ex. "SetSpeed")
+	Path string // Device path
+	Err  error  // Cause
+}
+
+func (e *SerialError) Error() string {
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause.
+func (e *SerialError) Unwrap() error {
+	return e.Err
+}
+
+// wrapErr wraps err in a SerialError for operation op, nil if err is nil.
+func (s *Serial) wrapErr(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SerialError{Op: op, Path: s.Name(), Err: err}
+}
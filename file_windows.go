@@ -0,0 +1,200 @@
+package serial
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var ErrTimeout = errors.New("i/o timeout")
+var ErrClosed = errors.New("file already closed")
+
+var (
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procCreateEventW        = kernel32.NewProc("CreateEventW")
+	procGetOverlappedResult = kernel32.NewProc("GetOverlappedResult")
+)
+
+// file is the serial file type on Windows, the role poll.File plays on unix systems.
+type file = File
+
+// File is a COM port handle opened for overlapped I/O, with read and write deadlines.
+// Reads and writes block until done or the deadline expires (ErrTimeout), Close unblocks them
+// (ErrClosed).
+type File struct {
+	h    syscall.Handle
+	name string
+	io   sync.RWMutex // Held for reading during I/O, Close takes it for writing
+
+	mu     sync.Mutex // Guards fields below
+	rdl    time.Time
+	wdl    time.Time
+	closed bool
+	tio    Termios // Attributes not kept in the DCB (see tcGetAttr)
+	ctrl   int     // DTR and RTS levels, the driver can't report them
+}
+
+// newFile wraps handle fd.
+func newFile(fd int, name string) (*file, error) {
+	return &File{h: syscall.Handle(fd), name: name}, nil
+}
+
+func closeFd(fd int) error {
+	return syscall.CloseHandle(syscall.Handle(fd))
+}
+
+// File returns serial file.
+func (s *Serial) File() *File {
+	return s.file()
+}
+
+// Name returns file name.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Fd returns file handle.
+func (f *File) Fd() uintptr {
+	return uintptr(f.h)
+}
+
+// SetDeadline sets read and write deadlines, zero means no deadline.
+func (f *File) SetDeadline(t time.Time) error {
+	f.mu.Lock()
+	f.rdl, f.wdl = t, t
+	f.mu.Unlock()
+	return nil
+}
+
+// SetReadDeadline sets read deadline, zero means no deadline.
+// Pending reads see the new deadline.
+func (f *File) SetReadDeadline(t time.Time) error {
+	f.mu.Lock()
+	f.rdl = t
+	f.mu.Unlock()
+	return nil
+}
+
+// SetWriteDeadline sets write deadline, zero means no deadline.
+// Pending writes see the new deadline.
+func (f *File) SetWriteDeadline(t time.Time) error {
+	f.mu.Lock()
+	f.wdl = t
+	f.mu.Unlock()
+	return nil
+}
+
+// Read reads up to len(b) bytes, returning as soon as some bytes are received.
+func (f *File) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	for {
+		n, err := f.overlapped(b, true)
+		if n > 0 || err != nil {
+			return n, err
+		}
+		// Total read timeout elapsed without data, keep waiting.
+	}
+}
+
+// Write writes b, returning the bytes written and ErrTimeout if the deadline expires first.
+func (f *File) Write(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	return f.overlapped(b, false)
+}
+
+// Close cancels pending I/O and closes the handle.
+func (f *File) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return ErrClosed
+	}
+	f.closed = true
+	f.mu.Unlock()
+	syscall.CancelIoEx(f.h, nil)
+	f.io.Lock()
+	defer f.io.Unlock()
+	return syscall.CloseHandle(f.h)
+}
+
+// state returns the deadline for reads or writes and whether f is closed.
+func (f *File) state(read bool) (time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if read {
+		return f.rdl, f.closed
+	}
+	return f.wdl, f.closed
+}
+
+// overlapped issues an overlapped read or write and waits for it, polling the deadline
+// (which may change meanwhile) and the closed state. Expired or closed I/O is canceled.
+func (f *File) overlapped(b []byte, read bool) (int, error) {
+	f.io.RLock()
+	defer f.io.RUnlock()
+	dl, closed := f.state(read)
+	if closed {
+		return 0, ErrClosed
+	}
+	if !dl.IsZero() && !time.Now().Before(dl) {
+		return 0, ErrTimeout
+	}
+	ev, _, e := procCreateEventW.Call(0, 1, 0, 0)
+	if ev == 0 {
+		return 0, e
+	}
+	defer syscall.CloseHandle(syscall.Handle(ev))
+	ov := syscall.Overlapped{HEvent: syscall.Handle(ev)}
+	var done uint32
+	var err error
+	if read {
+		err = syscall.ReadFile(f.h, b, &done, &ov)
+	} else {
+		err = syscall.WriteFile(f.h, b, &done, &ov)
+	}
+	if err != nil && err != syscall.ERROR_IO_PENDING {
+		return 0, err
+	}
+	var res error
+	for {
+		dl, closed = f.state(read)
+		if closed {
+			res = ErrClosed
+			break
+		}
+		step := 20 * time.Millisecond
+		if !dl.IsZero() {
+			left := time.Until(dl)
+			if left <= 0 {
+				res = ErrTimeout
+				break
+			}
+			if left < step {
+				step = left
+			}
+		}
+		ms := uint32((step + time.Millisecond - 1) / time.Millisecond)
+		if r, _ := syscall.WaitForSingleObject(syscall.Handle(ev), ms); r == syscall.WAIT_OBJECT_0 {
+			break
+		}
+	}
+	if res != nil {
+		syscall.CancelIoEx(f.h, &ov)
+	}
+	// Wait for completion, bytes transferred before cancellation are reported.
+	r, _, e := procGetOverlappedResult.Call(uintptr(f.h), uintptr(unsafe.Pointer(&ov)),
+		uintptr(unsafe.Pointer(&done)), 1)
+	if r == 0 && e != syscall.ERROR_OPERATION_ABORTED && res == nil {
+		res = e
+	}
+	if read && done > 0 {
+		res = nil
+	}
+	return int(done), res
+}
@@ -0,0 +1,48 @@
+package serial
+
+import (
+	"sync"
+	"time"
+)
+
+// StartIdleFill writes the fill byte b every interval while the port is otherwise idle
+// (ticks with application writes in between are skipped).
+// Fill bytes are written under the write lock, so they never split a Write.
+// It returns a function that stops the filler and waits for it to finish.
+// No filler is started if interval is not positive, the returned function does nothing.
+func (s *Serial) StartIdleFill(b byte, interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		s.wmu.Lock()
+		last := s.wn
+		s.wmu.Unlock()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			s.wmu.Lock()
+			if s.wn == last {
+				if _, err := s.write([]byte{b}); err != nil {
+					s.wmu.Unlock()
+					return
+				}
+			}
+			last = s.wn
+			s.wmu.Unlock()
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
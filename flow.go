@@ -0,0 +1,130 @@
+package serial
+
+import (
+	"errors"
+	"time"
+)
+
+// FlowState is a snapshot of the flow control picture of a link.
+type FlowState struct {
+	HwFlow     bool // RTS/CTS flow control enabled
+	SwFlow     bool // XON/XOFF flow control enabled
+	RTS        bool // RTS level (our "clear to send" signal to the peer)
+	CTS        bool // CTS level (peer allows us to send)
+	OutWaiting int  // Bytes waiting on output buffer
+	Stalled    bool // Output pending while flow control is holding it (see FlowControlState)
+}
+
+// FlowControlState returns the flow control configuration along with the current
+// RTS/CTS levels and output queue.
+// The kernel doesn't expose whether an XOFF was received, so Stalled is a best effort guess:
+// output is pending and either hardware flow control is on with CTS low,
+// or software flow control is on and the queue doesn't shrink within one character time.
+func (s *Serial) FlowControlState() (FlowState, error) {
+	var st FlowState
+	var err error
+	if st.HwFlow, st.SwFlow, err = s.flowCtrl(); err != nil {
+		return st, err
+	}
+	ctr, err := s.getCtrl()
+	if err != nil {
+		return st, err
+	}
+	st.RTS = ctr&RTS != 0
+	st.CTS = ctr&CTS != 0
+	if st.OutWaiting, err = s.outWaiting(); err != nil {
+		return st, err
+	}
+	if st.OutWaiting > 0 {
+		if st.HwFlow && !st.CTS {
+			st.Stalled = true
+		} else if st.SwFlow {
+			ct, err := s.charTime()
+			if err != nil {
+				return st, err
+			}
+			time.Sleep(2 * ct)
+			n, err := s.outWaiting()
+			if err != nil {
+				return st, err
+			}
+			st.Stalled = n >= st.OutWaiting
+		}
+	}
+	return st, nil
+}
+
+// FlowBlocked reports whether output is currently paused by hardware flow control:
+// RTS/CTS flow control is enabled and the peer holds CTS low.
+// It returns false when hardware flow control is off.
+func (s *Serial) FlowBlocked() (bool, error) {
+	hw, _, err := s.flowCtrl()
+	if err != nil || !hw {
+		return false, err
+	}
+	ctr, err := s.getCtrl()
+	if err != nil {
+		return false, err
+	}
+	return ctr&CTS == 0, nil
+}
+
+// FlowMode selects flow control in SetFlowControlMode.
+type FlowMode int
+
+const (
+	FLOW_NONE FlowMode = iota // No flow control
+	FLOW_SW                   // XON/XOFF flow control
+	FLOW_HW                   // RTS/CTS flow control
+	FLOW_BOTH                 // XON/XOFF and RTS/CTS flow control
+)
+
+// SetFlowControlMode sets exactly flow control mode with a single attribute change.
+// With software flow control, ixany lets any received character (not only XON) resume output.
+// Kernel start/stop thresholds of the input buffer are fixed by the line discipline
+// and can't be configured.
+func (s *Serial) SetFlowControlMode(mode FlowMode, ixany bool) error {
+	if mode < FLOW_NONE || mode > FLOW_BOTH {
+		return s.wrapErr("SetFlowControlMode", errors.New("invalid flow control mode"))
+	}
+	hw := mode == FLOW_HW || mode == FLOW_BOTH
+	sw := mode == FLOW_SW || mode == FLOW_BOTH
+	if hw && sw && s.strict.Load() {
+		return s.wrapErr("SetFlowControlMode", strictError("hardware and software flow control enabled together"))
+	}
+	return s.wrapErr("SetFlowControlMode", s.updateAttr(func(t *Termios) error {
+		termHwFlowCtrl(t, hw)
+		termSwFlowCtrl(t, sw)
+		if !sw || !ixany {
+			termIxany(t, false)
+		}
+		return nil
+	}))
+}
+
+// Default software flow control characters.
+const (
+	XON  = 0x11 // DC1, resumes output
+	XOFF = 0x13 // DC3, stops output
+)
+
+// SendFlowChar sends the configured XON (xon == true) or XOFF character (VSTART/VSTOP,
+// XON/XOFF if disabled) to pause or resume a peer by hand, regardless of SetSwFlowCtrl.
+// The character is written as is, bypassing the write translation table.
+func (s *Serial) SendFlowChar(xon bool) error {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	c, def := t.CC(VSTOP), byte(XOFF)
+	if xon {
+		c, def = t.CC(VSTART), XON
+	}
+	if c == 0 || c == 0xff { // _POSIX_VDISABLE
+		c = def
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	_, err := s.writeRaw([]byte{c})
+	return err
+}
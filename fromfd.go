@@ -0,0 +1,34 @@
+package serial
+
+// FromFd wraps an already open serial descriptor (Ex. received from a parent process or
+// systemd) without reopening it, setting default params and applying opts like Open.
+// name is used as device path (see Name) and by Reopen.
+// With own == true serial takes ownership of fd: Close closes it. Otherwise fd is duplicated
+// and Close closes only the duplicate, leaving fd open for the caller.
+// Either way the descriptor is switched to non blocking mode, a flag shared with the caller's
+// descriptor.
+// On Windows fd is a COM port handle, which must be opened with FILE_FLAG_OVERLAPPED.
+func FromFd(fd uintptr, name string, own bool, opts ...Option) (*Serial, error) {
+	sfd := int(fd)
+	if !own {
+		var err error
+		if sfd, err = dup(sfd); err != nil {
+			return nil, err
+		}
+	}
+	if err := setNonblock(sfd); err != nil {
+		closeFd(sfd)
+		return nil, err
+	}
+	s, err := newSerial(sfd, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,102 @@
+package serial
+
+import (
+	"errors"
+	"time"
+)
+
+// readInterval holds VMIN/VTIME read timing emulated over the non blocking file.
+type readInterval struct {
+	min int           // VMIN
+	gap time.Duration // VTIME
+}
+
+// SetReadIntervalTimeout sets the classic termios VMIN (0-255 bytes) and VTIME
+// (0-255 tenths of second) read timing:
+//   vmin == 0, vtime == 0: Read returns at once what is available, maybe nothing (0, nil).
+//   vmin > 0,  vtime == 0: Read waits until vmin bytes (or len(b) if smaller) are received.
+//   vmin == 0, vtime > 0:  Read waits up to vtime for the first byte, returning (0, nil) if none arrives.
+//   vmin > 0,  vtime > 0:  after the first byte, Read returns when vmin bytes are received or
+//                          the line stays silent for vtime (inter byte timeout, Ex. Modbus RTU framing).
+// Serial is open non blocking, so these are emulated on top of the read deadlines, which keep bounding
+// the whole Read: ErrTimeout is returned when the deadline expires with nothing received.
+// Reads return what they got so far when the deadline expires with some bytes received.
+// SetReadIntervalTimeout(1, 0) restores the default behavior.
+func (s *Serial) SetReadIntervalTimeout(vmin, vtime int) error {
+	if vmin < 0 || vmin > 255 || vtime < 0 || vtime > 255 {
+		return s.wrapErr("SetReadIntervalTimeout", errors.New("vmin and vtime must be in range 0-255"))
+	}
+	gap := time.Duration(vtime) * time.Second / 10
+	if err := s.setReadTimeout(vmin, gap); err != nil {
+		return s.wrapErr("SetReadIntervalTimeout", err)
+	}
+	if vmin == 1 && vtime == 0 {
+		s.ivl.Store(nil)
+	} else {
+		s.ivl.Store(&readInterval{min: vmin, gap: gap})
+	}
+	return nil
+}
+
+// readFile reads from file once the read deadline is armed, applying VMIN/VTIME timing.
+func (s *Serial) readFile(b []byte) (int, error) {
+	ivl := s.ivl.Load()
+	if ivl == nil || len(b) == 0 {
+		return s.file().Read(b)
+	}
+	min := ivl.min
+	if min > len(b) {
+		min = len(b)
+	}
+	var n int
+	var err error
+	switch {
+	case min == 0 && ivl.gap == 0:
+		if avail, werr := s.inpWaiting(); werr != nil || avail == 0 {
+			return 0, werr
+		}
+		return s.file().Read(b)
+	case min == 0:
+		return s.readWithin(b, ivl.gap)
+	default:
+		n, err = s.file().Read(b)
+	}
+	for err == nil && n < min {
+		var m int
+		if ivl.gap > 0 {
+			m, err = s.readWithin(b[n:], ivl.gap)
+			if m == 0 && err == nil {
+				break // Inter byte timeout
+			}
+		} else {
+			m, err = s.file().Read(b[n:])
+		}
+		n += m
+	}
+	if n > 0 && err == ErrTimeout {
+		err = nil
+	}
+	return n, err
+}
+
+// readWithin reads bounded by d besides the read deadline in effect.
+// It returns (0, nil) when d expires first.
+func (s *Serial) readWithin(b []byte, d time.Duration) (int, error) {
+	s.dmu.Lock()
+	dl := s.readDeadline()
+	gapped := dl.IsZero() || time.Now().Add(d).Before(dl)
+	if gapped {
+		dl = time.Now().Add(d)
+	}
+	s.frdl = dl
+	err := s.file().SetReadDeadline(dl)
+	s.dmu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	n, err := s.file().Read(b)
+	if err == ErrTimeout && gapped {
+		return n, nil
+	}
+	return n, err
+}
@@ -0,0 +1,62 @@
+package serial
+
+import "sync"
+
+// StartLineReader reads lines (see ReadLine) in background, publishing them on the returned
+// lines channel. Read errors are published on the errors channel: timeouts don't stop the
+// reader, any other error (Ex. ErrClosed) is published last and both channels are closed.
+// The returned stop function ends the reader and waits for its goroutine to exit,
+// a partial line being read is kept (see Pending). Serial is not closed.
+// While the reader is running, serial must not be read from elsewhere.
+// The explicit read deadline (SetReadDeadline) doesn't apply to the reader, the default one does.
+func (s *Serial) StartLineReader() (<-chan string, <-chan error, func()) {
+	lines := make(chan string, 16)
+	errs := make(chan error, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	op := s.beginBackgroundRead()
+	go func() {
+		defer func() {
+			s.endRead(op)
+			close(lines)
+			close(errs)
+			close(exited)
+		}()
+		for {
+			line, err := s.ReadLine()
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err == ErrTimeout {
+				select {
+				case errs <- err:
+				default: // Previous timeout not taken yet
+				}
+				continue
+			}
+			if err != nil {
+				select {
+				case errs <- err:
+				case <-done:
+				}
+				return
+			}
+			select {
+			case lines <- line:
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			s.cancelRead(op) // Unblock pending ReadLine
+			<-exited
+		})
+	}
+	return lines, errs, stop
+}
@@ -0,0 +1,20 @@
+package serial
+
+import "errors"
+
+// ErrLocked is returned by Lock when another process holds the port lock.
+var ErrLocked = errors.New("port locked by another process")
+
+// Lock takes an advisory exclusive lock on the port (flock LOCK_EX), the convention used by
+// tools like gpsd to arbitrate serial ports. It doesn't wait: if another process holds
+// the lock it returns ErrLocked. Unlike SetExclusive, it only coordinates with processes
+// that also lock the device. The lock is released by Unlock or Close.
+// On Windows, where COM ports are always opened exclusively, it does nothing.
+func (s *Serial) Lock() error {
+	return s.lock()
+}
+
+// Unlock releases the lock taken by Lock.
+func (s *Serial) Unlock() error {
+	return s.unlock()
+}
@@ -0,0 +1,30 @@
+package serial
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoopbackTest checks a serial with TX wired to RX: it flushes both buffers, writes pattern
+// and reads it back within timeout. It returns nil if the echo matches exactly, otherwise an
+// error telling the first mismatching offset or how many bytes came back.
+func (s *Serial) LoopbackTest(pattern []byte, timeout time.Duration) error {
+	if err := s.Flush(FLUSH_IO); err != nil {
+		return err
+	}
+	defer s.endRead(s.beginRead(timeout))
+	if _, err := s.WriteFull(pattern); err != nil {
+		return fmt.Errorf("loopback write: %w", err)
+	}
+	echo := make([]byte, len(pattern))
+	n, err := s.readFull(echo)
+	for i := 0; i < n; i++ {
+		if echo[i] != pattern[i] {
+			return fmt.Errorf("loopback mismatch at offset %d: got 0x%02x, want 0x%02x", i, echo[i], pattern[i])
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("loopback: %d of %d bytes received: %w", n, len(pattern), err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package serial
+
+// OpenPair opens a pair of connected ports backed by a pseudo terminal, bytes written on a
+// are read from b and vice versa. It's meant for testing protocol code without hardware.
+// Termios based settings work as on a real port, modem control line operations fail.
+// Each port must be closed on its own.
+func OpenPair() (a, b *Serial, err error) {
+	mfd, name, err := openPTY()
+	if err != nil {
+		return nil, nil, err
+	}
+	if a, err = newSerial(mfd, "/dev/ptmx"); err != nil {
+		return nil, nil, err
+	}
+	if b, err = Open(name); err != nil {
+		a.Close()
+		return nil, nil, err
+	}
+	return a, b, nil
+}
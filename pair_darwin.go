@@ -0,0 +1,33 @@
+package serial
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY opens a pseudo terminal master, returning its descriptor and the slave path.
+func openPTY() (int, string, error) {
+	mfd, err := syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return -1, "", err
+	}
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(mfd), syscall.TIOCPTYGRANT, 0); e != 0 {
+		syscall.Close(mfd)
+		return -1, "", os.NewSyscallError("grantpt", e)
+	}
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(mfd), syscall.TIOCPTYUNLK, 0); e != 0 {
+		syscall.Close(mfd)
+		return -1, "", os.NewSyscallError("unlockpt", e)
+	}
+	var name [128]byte
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(mfd), syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0]))); e != 0 {
+		syscall.Close(mfd)
+		return -1, "", os.NewSyscallError("ptsname", e)
+	}
+	if i := bytes.IndexByte(name[:], 0); i >= 0 {
+		return mfd, string(name[:i]), nil
+	}
+	return mfd, string(name[:]), nil
+}
@@ -0,0 +1,26 @@
+package serial
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// openPTY opens a pseudo terminal master, returning its descriptor and the slave path.
+func openPTY() (int, string, error) {
+	mfd, err := syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return -1, "", err
+	}
+	var n uint32
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(mfd), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&n))); e != 0 {
+		syscall.Close(mfd)
+		return -1, "", os.NewSyscallError("unlockpt", e)
+	}
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(mfd), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); e != 0 {
+		syscall.Close(mfd)
+		return -1, "", os.NewSyscallError("ptsname", e)
+	}
+	return mfd, fmt.Sprintf("/dev/pts/%d", n), nil
+}
@@ -0,0 +1,6 @@
+package serial
+
+// openPTY is unsupported, Windows has no pseudo terminals.
+func openPTY() (int, string, error) {
+	return -1, "", ErrUnsupported
+}
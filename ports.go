@@ -0,0 +1,141 @@
+package serial
+
+import (
+	"sync"
+	"time"
+)
+
+// PortInfo describes a serial port found by List.
+type PortInfo struct {
+	Name         string // Device path (Ex. "/dev/ttyUSB0")
+	VID          uint16 // USB vendor ID (0 if not USB or unknown)
+	PID          uint16 // USB product ID (0 if not USB or unknown)
+	Manufacturer string // USB manufacturer string, if available
+	Product      string // USB product string, if available
+	SerialNumber string // USB serial number string, if available
+}
+
+// List returns the serial ports present on the system, sorted by name.
+// USB metadata is filled in when the platform exposes it.
+func List() ([]PortInfo, error) {
+	return listPorts()
+}
+
+// USBBulkSize returns the max packet size of the USB bulk in endpoint backing serial
+// (Ex. 64 for full speed FTDI/CDC adapters, 512 for high speed ones).
+// It returns ErrUnsupported for non USB ports.
+func (s *Serial) USBBulkSize() (int, error) {
+	return s.usbBulkSize()
+}
+
+// PortEvent kinds.
+const (
+	PORT_ADDED   = iota // Port appeared
+	PORT_REMOVED        // Port disappeared
+)
+
+// PortEvent reports a serial port arrival or removal.
+type PortEvent struct {
+	Kind int      // PORT_ADDED or PORT_REMOVED
+	Port PortInfo // Port description (as last listed for removals)
+}
+
+// watchPollInterval is how often ports are listed when the platform can't notify changes.
+const watchPollInterval = time.Second
+
+// watchSettle is the delay between a change notification and listing ports,
+// so drivers finish registering the device.
+const watchSettle = 100 * time.Millisecond
+
+// Watch notifies serial ports arrivals and removals (Ex. a USB adapter plugged in),
+// as seen by List. Ports present when Watch is called are not reported.
+// On Linux device nodes are watched with inotify; where it isn't available (and on other
+// platforms) ports are listed periodically. The returned stop function ends watching
+// and closes the events channel.
+func Watch() (<-chan PortEvent, func(), error) {
+	ports, err := List()
+	if err != nil {
+		return nil, nil, err
+	}
+	wake, closeWake := watchPorts()
+	if wake == nil {
+		t := time.NewTicker(watchPollInterval)
+		tick := make(chan struct{}, 1)
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-t.C:
+					select {
+					case tick <- struct{}{}:
+					default:
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+		wake = tick
+		closeWake = func() {
+			t.Stop()
+			close(done)
+		}
+	}
+	events := make(chan PortEvent)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer close(events)
+		known := map[string]PortInfo{}
+		for _, p := range ports {
+			known[p.Name] = p
+		}
+		for {
+			select {
+			case <-wake:
+			case <-done:
+				return
+			}
+			select {
+			case <-time.After(watchSettle):
+			case <-done:
+				return
+			}
+			ports, err := List()
+			if err != nil {
+				continue
+			}
+			var evs []PortEvent
+			now := map[string]PortInfo{}
+			for _, p := range ports {
+				now[p.Name] = p
+				if _, ok := known[p.Name]; !ok {
+					evs = append(evs, PortEvent{Kind: PORT_ADDED, Port: p})
+				}
+			}
+			for name, p := range known {
+				if _, ok := now[name]; !ok {
+					evs = append(evs, PortEvent{Kind: PORT_REMOVED, Port: p})
+				}
+			}
+			known = now
+			for _, ev := range evs {
+				select {
+				case events <- ev:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			closeWake()
+			<-exited
+		})
+	}
+	return events, stop, nil
+}
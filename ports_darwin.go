@@ -0,0 +1,31 @@
+package serial
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// listPorts lists callout devices (/dev/cu.*), the ones meant for opening serial ports
+// on Darwin. USB metadata lives in IOKit, not reachable without cgo, so only names are filled.
+func listPorts() ([]PortInfo, error) {
+	names, err := filepath.Glob("/dev/cu.*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	ports := make([]PortInfo, 0, len(names))
+	for _, name := range names {
+		ports = append(ports, PortInfo{Name: name})
+	}
+	return ports, nil
+}
+
+// usbBulkSize is unsupported, USB endpoint descriptors live in IOKit.
+func (s *Serial) usbBulkSize() (int, error) {
+	return 0, ErrUnsupported
+}
+
+// watchPorts returns nil, Darwin device nodes are polled (see Watch).
+func watchPorts() (<-chan struct{}, func()) {
+	return nil, nil
+}
@@ -0,0 +1,37 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// maxComPorts is the highest COM port number probed by listPorts.
+const maxComPorts = 256
+
+// listPorts probes COM1 to COM256. Ports in use by other processes open with access
+// denied and are listed too. USB metadata lives in SetupAPI, so only names are filled.
+func listPorts() ([]PortInfo, error) {
+	ports := []PortInfo{}
+	for i := 1; i <= maxComPorts; i++ {
+		name := fmt.Sprintf("COM%d", i)
+		fd, err := open(name, syscall.O_RDWR)
+		if err == nil {
+			closeFd(fd)
+		} else if !errors.Is(err, syscall.ERROR_ACCESS_DENIED) {
+			continue
+		}
+		ports = append(ports, PortInfo{Name: name})
+	}
+	return ports, nil
+}
+
+// usbBulkSize is unsupported, USB endpoint descriptors live in SetupAPI.
+func (s *Serial) usbBulkSize() (int, error) {
+	return 0, ErrUnsupported
+}
+
+// watchPorts returns nil, Windows ports are polled (see Watch).
+func watchPorts() (<-chan struct{}, func()) {
+	return nil, nil
+}
@@ -0,0 +1,174 @@
+package serial
+
+import (
+	"syscall"
+	"time"
+)
+
+// pollFd mirrors struct pollfd of poll(2).
+type pollFd struct {
+	fd      int32
+	events  int16
+	revents int16
+}
+
+const pollIn = 0x1 // POLLIN
+
+// ReadV reads into several buffers in order with a single readv(2) call, so the kernel
+// scatters received bytes (Ex. header and body of a frame) without intermediate copies.
+// It blocks (honoring read deadline) until some bytes are available and returns the total
+// bytes read. Bytes read ahead (ReadByte, Peek...) are returned first, without syscall.
+// With SuppressEcho set, bytes are gathered to strip the echo and scattered back.
+// A prepared set of buffers can be passed as ReadV(bufs...) (Ex. [][]byte{header, body}).
+func (s *Serial) ReadV(bufs ...[]byte) (int, error) {
+	if vecLen(bufs) == 0 {
+		return 0, nil
+	}
+	return s.readWith(func() (int, error) { return s.readRawV(bufs) })
+}
+
+// readRawV is the readRaw counterpart of ReadV.
+func (s *Serial) readRawV(bufs [][]byte) (int, error) {
+	if s.buffered() > 0 {
+		n := 0
+		for _, b := range bufs {
+			m := s.readBuffered(b)
+			n += m
+			if m < len(b) {
+				break
+			}
+		}
+		return n, nil
+	}
+	for {
+		n, err := s.readOSV(bufs)
+		if n == 0 || !s.SuppressEcho {
+			return n, err
+		}
+		tmp := make([]byte, n)
+		vecCopy(tmp, bufs)
+		m, eerr := s.dropEcho(tmp)
+		if eerr != nil {
+			return 0, eerr
+		}
+		vecScatter(bufs, tmp[:m])
+		if m > 0 || err != nil {
+			return m, err
+		}
+		// Only our own echo arrived, wait for more.
+	}
+}
+
+// readOSV reads from the OS into bufs like readOS, waiting for input and then
+// issuing a single readv.
+func (s *Serial) readOSV(bufs [][]byte) (int, error) {
+	limit := vecLen(bufs)
+	if max := int(s.rmax.Load()); max > 0 && limit > max {
+		limit = max
+	}
+	rate := int(s.rrate.Load())
+	if chunk := rate / 100; rate > 0 && limit > chunk {
+		if chunk == 0 {
+			chunk = 1
+		}
+		limit = chunk
+	}
+	bufs = vecTrunc(bufs, limit)
+	var n int
+	var err error
+	for {
+		if err = s.waitReadable(); err != nil {
+			break
+		}
+		if n, err = s.readv(bufs); err != syscall.EAGAIN {
+			break
+		}
+	}
+	if n > 0 {
+		s.stamp()
+	}
+	if rate > 0 {
+		time.Sleep(time.Duration(n) * time.Second / time.Duration(rate))
+	}
+	left := n
+	for _, b := range bufs {
+		if left == 0 {
+			break
+		}
+		if len(b) > left {
+			b = b[:left]
+		}
+		s.translateRx(b)
+		s.trace('R', b)
+		left -= len(b)
+	}
+	s.ctr.read.Add(uint64(n))
+	return n, err
+}
+
+// waitReadable waits until input is available, up to the read deadline in effect.
+// Readiness is polled in steps so canceled read operations are noticed.
+func (s *Serial) waitReadable() error {
+	s.dmu.Lock()
+	dl := s.readDeadline()
+	s.dmu.Unlock()
+	for {
+		step := 20 * time.Millisecond
+		if !dl.IsZero() {
+			left := time.Until(dl)
+			if left <= 0 {
+				return ErrTimeout
+			}
+			if left < step {
+				step = left
+			}
+		}
+		if ready, err := s.waitIn(step); ready || err != nil {
+			return err
+		}
+		s.dmu.Lock()
+		if cur := s.readDeadline(); !cur.IsZero() && (dl.IsZero() || cur.Before(dl)) {
+			dl = cur
+		}
+		s.dmu.Unlock()
+	}
+}
+
+// vecLen returns the total length of bufs.
+func vecLen(bufs [][]byte) int {
+	n := 0
+	for _, b := range bufs {
+		n += len(b)
+	}
+	return n
+}
+
+// vecTrunc returns bufs limited to n bytes in total.
+func vecTrunc(bufs [][]byte, n int) [][]byte {
+	res := make([][]byte, 0, len(bufs))
+	for _, b := range bufs {
+		if n == 0 {
+			break
+		}
+		if len(b) > n {
+			b = b[:n]
+		}
+		res = append(res, b)
+		n -= len(b)
+	}
+	return res
+}
+
+// vecCopy copies bufs in order into dst.
+func vecCopy(dst []byte, bufs [][]byte) {
+	for _, b := range bufs {
+		dst = dst[copy(dst, b):]
+	}
+}
+
+// vecScatter copies src in order into bufs.
+func vecScatter(bufs [][]byte, src []byte) {
+	for _, b := range bufs {
+		src = src[copy(b, src):]
+	}
+}
@@ -0,0 +1,55 @@
+package serial
+
+import "errors"
+
+// ReadRecord reads a fixed-width record of exactly width bytes.
+// On timeout or I/O error it returns the partial record read so far along with the error.
+func (s *Serial) ReadRecord(width int) ([]byte, error) {
+	if width <= 0 {
+		return nil, errors.New("invalid record width")
+	}
+	b := make([]byte, width)
+	n, err := s.readFull(b)
+	return b[:n], err
+}
+
+// ReadExactly reads exactly len(b) bytes into b. If the read deadline expires first it returns
+// the partial count with ErrTimeout (other errors are returned likewise).
+// The default read timeout bounds the whole call rather than each read.
+func (s *Serial) ReadExactly(b []byte) (int, error) {
+	s.dmu.Lock()
+	d := s.rto
+	s.dmu.Unlock()
+	defer s.endRead(s.beginRead(d))
+	return s.readFull(b)
+}
+
+// RecordReader iterates over a stream of fixed-width records without delimiters.
+type RecordReader struct {
+	s     *Serial
+	width int
+	rec   []byte
+	err   error
+}
+
+// NewRecordReader returns a RecordReader reading width bytes records from serial.
+func NewRecordReader(s *Serial, width int) *RecordReader {
+	return &RecordReader{s: s, width: width}
+}
+
+// Next reads the next record. It returns false on timeout or I/O error, in that case
+// Record returns the partial record and Err returns the error.
+func (r *RecordReader) Next() bool {
+	r.rec, r.err = r.s.ReadRecord(r.width)
+	return r.err == nil
+}
+
+// Record returns the last record read by Next.
+func (r *RecordReader) Record() []byte {
+	return r.rec
+}
+
+// Err returns the error that stopped the iteration.
+func (r *RecordReader) Err() error {
+	return r.err
+}
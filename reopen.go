@@ -0,0 +1,69 @@
+package serial
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// deviceLost reports whether read error err means the device went away
+// (Ex. unplugged USB adapter).
+func deviceLost(err error) bool {
+	return errors.Is(err, syscall.EIO) ||
+		errors.Is(err, syscall.ENXIO) ||
+		errors.Is(err, syscall.ENODEV) ||
+		errors.Is(err, ErrDeviceGone) ||
+		errors.Is(err, ErrDisconnected)
+}
+
+// disconnected maps device loss errors to ErrDisconnected.
+func disconnected(err error) error {
+	if err != nil && deviceLost(err) {
+		return ErrDisconnected
+	}
+	return err
+}
+
+// Reopen opens the same path again and replaces serial file with the new one, restoring
+// the last applied attributes with a single attribute change. Bytes read ahead are discarded.
+// If the device is still gone the open error is returned and the current file is kept,
+// so next operations fail the same way and Reopen can be retried.
+// Operations in progress on the replaced file when it's closed return ErrClosed.
+// Settings kept outside termios (modem control lines, exclusive mode, RS-485) are not restored.
+func (s *Serial) Reopen() error {
+	name := s.Name()
+	fd, err := open(name, s.flags|s.mode.oflag())
+	if err != nil {
+		s.lost.Store(true)
+		return err
+	}
+	f, err := newFile(fd, name)
+	if err != nil {
+		closeFd(fd)
+		s.lost.Store(true)
+		return err
+	}
+	s.lost.Store(false)
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	s.dmu.Lock()
+	old := s.f.Swap(f)
+	s.frdl, s.fwdl = time.Time{}, time.Time{}
+	s.dmu.Unlock()
+	old.Close()
+	s.dropInput()
+	return s.tcSetAttr(s.attr)
+}
+
+// OpenResilient opens serial like Open, with reads that survive device loss: when a read fails
+// because the device went away, serial is reopened (see Reopen) and the read retried once.
+// If the device is not back yet ErrDisconnected is returned and next read reopens again
+// before reading.
+func OpenResilient(path string, opts ...Option) (*Serial, error) {
+	s, err := Open(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.resil.Store(true)
+	return s, nil
+}
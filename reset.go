@@ -0,0 +1,110 @@
+package serial
+
+import "time"
+
+// ResetArduino resets an Arduino style board (auto-reset capacitor on DTR),
+// dropping DTR and RTS for 250ms and raising them again, like avrdude does.
+func (s *Serial) ResetArduino() error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	if err := s.setCtrlBit(DTR|RTS, false); err != nil {
+		return err
+	}
+	time.Sleep(250 * time.Millisecond)
+	if err := s.setCtrlBit(DTR|RTS, true); err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return nil
+}
+
+// ResetESP32Bootloader resets an ESP32/ESP8266 into the serial bootloader using the
+// usual DTR->IO0, RTS->EN transistor circuit (esptool "classic reset" sequence).
+func (s *Serial) ResetESP32Bootloader() error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	if err := s.setCtrlBit(DTR, false); err != nil { // IO0 high
+		return err
+	}
+	if err := s.setCtrlBit(RTS, true); err != nil { // EN low, chip in reset
+		return err
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := s.setCtrlBit(DTR, true); err != nil { // IO0 low
+		return err
+	}
+	if err := s.setCtrlBit(RTS, false); err != nil { // EN high, chip out of reset
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return s.setCtrlBit(DTR, false) // IO0 high, done
+}
+
+// ResetESP32Run resets an ESP32/ESP8266 into normal run mode (esptool "hard reset").
+func (s *Serial) ResetESP32Run() error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	if err := s.setCtrlBit(DTR, false); err != nil { // IO0 high
+		return err
+	}
+	if err := s.setCtrlBit(RTS, true); err != nil { // EN low, chip in reset
+		return err
+	}
+	time.Sleep(100 * time.Millisecond)
+	return s.setCtrlBit(RTS, false) // EN high, chip out of reset
+}
+
+// PulseDTR drops DTR for duration low and raises it again (Ex. auto-reset boards).
+// DTR is raised again even if dropping it fails halfway.
+func (s *Serial) PulseDTR(low time.Duration) error {
+	return s.pulse(DTR, low)
+}
+
+// PulseRTS drops RTS for duration low and raises it again.
+// RTS is raised again even if dropping it fails halfway.
+func (s *Serial) PulseRTS(low time.Duration) error {
+	return s.pulse(RTS, low)
+}
+
+// pulse drops control line ctl for duration low.
+func (s *Serial) pulse(ctl int, low time.Duration) (err error) {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	defer func() {
+		if rerr := s.setCtrlBit(ctl, true); err == nil {
+			err = rerr
+		}
+	}()
+	if err = s.setCtrlBit(ctl, false); err != nil {
+		return err
+	}
+	time.Sleep(low)
+	return nil
+}
+
+// CtrlStep is a step of a control line sequence (see PulseSequence).
+type CtrlStep struct {
+	Ctrl  int           // Control line(s) (Ex. DTR, RTS or DTR|RTS)
+	Level bool          // Level set
+	Hold  time.Duration // Time to wait after setting the level
+}
+
+// PulseSequence sets control lines as told by steps, in order, waiting each step Hold
+// (Ex. RTS low, wait 100ms, DTR high, wait 50ms, RTS high).
+// If a step fails, the modem control word found before the sequence is restored.
+func (s *Serial) PulseSequence(steps []CtrlStep) (err error) {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	orig, err := s.getCtrl()
+	if err != nil {
+		return err
+	}
+	for _, st := range steps {
+		if err = s.setCtrlBit(st.Ctrl, st.Level); err != nil {
+			s.setCtrl(orig)
+			return err
+		}
+		time.Sleep(st.Hold)
+	}
+	return nil
+}
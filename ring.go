@@ -0,0 +1,103 @@
+package serial
+
+import "sync"
+
+// Ring keeps the last bytes received by CaptureRing.
+type Ring struct {
+	mu    sync.Mutex
+	buf   []byte
+	next  int   // Write position
+	full  bool  // Buffer wrapped at least once
+	total int64 // Bytes captured since start
+	err   error
+}
+
+// write appends b, overwriting the oldest bytes when the buffer is full.
+func (r *Ring) write(b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += int64(len(b))
+	if len(b) >= len(r.buf) {
+		copy(r.buf, b[len(b)-len(r.buf):])
+		r.next, r.full = 0, true
+		return
+	}
+	n := copy(r.buf[r.next:], b)
+	if n < len(b) {
+		copy(r.buf, b[n:])
+		r.full = true
+	}
+	r.next = (r.next + len(b)) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Snapshot returns a copy of the captured bytes, oldest first.
+func (r *Ring) Snapshot() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]byte(nil), r.buf[:r.next]...)
+	}
+	return append(append([]byte(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
+
+// Total returns the number of bytes captured, including the ones already overwritten.
+func (r *Ring) Total() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
+
+// Err returns the read error that stopped capturing (nil while running or when stopped).
+func (r *Ring) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// CaptureRing reads serial in background keeping the last size bytes received in a Ring,
+// so memory stays fixed however long it runs. Timeouts don't stop capturing, other read
+// errors do (see Ring.Err). The returned stop function ends capturing and waits for the
+// reader goroutine to exit. While capturing, serial must not be read from elsewhere.
+// The explicit read deadline (SetReadDeadline) doesn't apply while capturing.
+func (s *Serial) CaptureRing(size int) (*Ring, func()) {
+	if size <= 0 {
+		size = 1
+	}
+	r := &Ring{buf: make([]byte, size)}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	op := s.beginBackgroundRead()
+	go func() {
+		defer close(exited)
+		defer s.endRead(op)
+		buf := make([]byte, 4096)
+		for {
+			n, err := s.Read(buf)
+			select {
+			case <-done:
+				r.write(buf[:n])
+				return
+			default:
+			}
+			r.write(buf[:n])
+			if err != nil && err != ErrTimeout {
+				r.mu.Lock()
+				r.err = err
+				r.mu.Unlock()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			s.cancelRead(op)
+			<-exited
+		})
+	}
+	return r, stop
+}
@@ -0,0 +1,25 @@
+package serial
+
+import "time"
+
+// RS485Config configures RS-485 half duplex mode with driver controlled RTS direction.
+type RS485Config struct {
+	Enabled         bool          // RS-485 mode enabled
+	RtsOnSend       bool          // RTS level while sending (true = high)
+	RtsAfterSend    bool          // RTS level after sending (true = high)
+	RxDuringTx      bool          // Keep receiving while sending (own echo)
+	DelayBeforeSend time.Duration // RTS to data delay, millisecond resolution
+	DelayAfterSend  time.Duration // Data end to RTS release delay, millisecond resolution
+}
+
+// SetRS485 configures RS-485 mode. It returns ErrUnsupported if the adapter or driver
+// doesn't support it.
+func (s *Serial) SetRS485(cfg RS485Config) error {
+	return s.wrapErr("SetRS485", s.setRS485(cfg))
+}
+
+// RS485 returns current RS-485 configuration. It returns ErrUnsupported if the adapter
+// or driver doesn't support RS-485 mode.
+func (s *Serial) RS485() (RS485Config, error) {
+	return s.getRS485()
+}
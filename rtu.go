@@ -0,0 +1,33 @@
+package serial
+
+import "time"
+
+// InterFrameDelay returns the Modbus RTU inter frame silence for current serial settings:
+// 3.5 character times, each character counting start, data, parity and stop bits.
+// Above 19200 baud the spec fixes it at 1750µs.
+func (s *Serial) InterFrameDelay() (time.Duration, error) {
+	speed, err := s.speed()
+	if err != nil {
+		return 0, err
+	}
+	if speed > 19200 {
+		return 1750 * time.Microsecond, nil
+	}
+	ct, err := s.charTime()
+	if err != nil {
+		return 0, err
+	}
+	return ct * 7 / 2, nil
+}
+
+// ReadRTUFrame reads a Modbus RTU frame: bytes until the line stays silent for the
+// inter frame delay (see InterFrameDelay and ReadFrame), up to max bytes (0 = unlimited).
+// Timing depends on the driver delivering bytes promptly, USB adapters with a long
+// latency timer may split or merge frames.
+func (s *Serial) ReadRTUFrame(max int) ([]byte, error) {
+	d, err := s.InterFrameDelay()
+	if err != nil {
+		return nil, err
+	}
+	return s.ReadFrame(d, max)
+}
@@ -0,0 +1,99 @@
+package serial
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// inSet reports whether c is one of the bytes in set.
+func inSet(c byte, set string) bool {
+	for i := 0; i < len(set); i++ {
+		if c == set[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// indexAny returns the index of the first byte of data contained in set, -1 if none.
+func indexAny(data []byte, set string) int {
+	for i, c := range data {
+		if inSet(c, set) {
+			return i
+		}
+	}
+	return -1
+}
+
+// stripAny returns a copy of data without the bytes contained in set.
+func stripAny(data []byte, set string) []byte {
+	res := make([]byte, 0, len(data))
+	for _, c := range data {
+		if !inSet(c, set) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// lineIgnore returns the bytes ignored when reading lines: Serial.LineIgnore,
+// minus the terminator bytes in text mode.
+func (s *Serial) lineIgnore() string {
+	if !s.TextMode || indexAny([]byte(s.LineIgnore), s.LineEnd) < 0 {
+		return s.LineIgnore
+	}
+	return string(stripAny([]byte(s.LineIgnore), s.LineEnd))
+}
+
+// SplitLines is a bufio.SplitFunc splitting lines like ReadLine does:
+// Serial.LineEnd characters end lines (they are not part of tokens)
+// and Serial.LineIgnore characters are removed.
+// In text mode (Serial.TextMode) lines end at the whole LineEnd sequence.
+// At EOF (or read error) a final line without end is returned as last token.
+func (s *Serial) SplitLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	ign := s.lineIgnore()
+	if s.TextMode && len(s.LineEnd) > 0 {
+		if i := bytes.Index(data, []byte(s.LineEnd)); i >= 0 {
+			return i + len(s.LineEnd), stripAny(data[:i], ign), nil
+		}
+	} else if i := indexAny(data, s.LineEnd); i >= 0 {
+		return i + 1, stripAny(data[:i], ign), nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), stripAny(data, ign), nil
+	}
+	return 0, nil, nil
+}
+
+// SplitDelim returns a bufio.SplitFunc splitting data at delim (not part of tokens).
+// At EOF (or read error) final data without delim is returned as last token.
+func SplitDelim(delim byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		for i, c := range data {
+			if c == delim {
+				return i + 1, data[:i], nil
+			}
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// Scanner returns a bufio.Scanner reading lines from serial (see SplitLines).
+// Scanning stops on the first read error, including timeout (check Err);
+// a partial line pending at that moment is not returned as token but kept in serial,
+// so next Scanner, ReadLine or Read resumes with it.
+// Bytes read ahead by the scanner are lost if scanning is abandoned before an error.
+func (s *Serial) Scanner() *bufio.Scanner {
+	sc := bufio.NewScanner(s)
+	sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if adv, tok, err := s.SplitLines(data, false); adv > 0 || err != nil || !atEOF {
+			return adv, tok, err
+		}
+		s.unread(data)
+		return len(data), nil, nil
+	})
+	return sc
+}
@@ -1,153 +1,867 @@
 package serial
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
-
-	"github.com/jaracil/poll"
 )
 
+// Serial is an open serial port.
+//
+// Concurrency: one goroutine reading and another one writing need no extra locking.
+// Writes (Write, WriteString, WriteFull, WriteFrame...) are serialized among them.
+// Attribute changes (SetSpeed, SetParity, ApplyConfig, SetAttr...) and control line
+// sequences (TestControlLine, pulses) are serialized too, so they can be called from any goroutine.
+// Concurrent readers are not supported: reads consuming lines or frames (ReadLine, ReadUntil,
+// WaitForRe, ATCommand...) must be issued from a single goroutine at a time.
+// The LineIgnore and LineEnd fields must not be changed while reading.
 type Serial struct {
-	f *poll.File
+	f atomic.Pointer[file] // Replaced by Reopen, use file()
+
+	wmu   sync.Mutex    // Serializes writes
+	cmu   sync.Mutex    // Serializes attribute and control line changes
+	attr  *Termios      // Last applied attributes, guarded by cmu
+	flags int           // Extra open flags, kept for Reopen
+	mode  AccessMode    // Access mode, kept for Reopen
+	wn    uint64        // Number of writes done, guarded by wmu
+	ifg   time.Duration // Inter frame gap, guarded by wmu
+	amu   sync.Mutex
+	atail chan struct{} // Closed when last queued WriteAsync completes, guarded by amu
+
+	dmu  sync.Mutex    // Guards deadline state below
+	rdl  time.Time     // Explicit read deadline
+	wdl  time.Time     // Explicit write deadline
+	rto  time.Duration // Default read timeout
+	wto  time.Duration // Default write timeout
+	rop  *readOp       // Read operation in progress
+	frdl time.Time     // Read deadline set on file
+	fwdl time.Time     // Write deadline set on file
+
+	rxlat  atomic.Pointer[[256]byte] // Read translation table
+	txlat  atomic.Pointer[[256]byte] // Write translation table
+	rmax   atomic.Int64              // Max bytes requested by a single read syscall
+	strict atomic.Bool               // Strict mode
+	rrate  atomic.Int64              // Simulated consumer rate in bytes per second
+	eion   atomic.Int64              // Read retries after EIO
+	eiod   atomic.Int64              // Delay before each EIO retry
+	idle   atomic.Int64              // Idle timeout (see SetIdleTimeout)
+
+	ivl   atomic.Pointer[readInterval] // VMIN/VTIME read timing (nil = default)
+	resil atomic.Bool                  // Reopen on device loss while reading
+	lost  atomic.Bool                  // Last Reopen failed, retried by next resilient read
+
+	ctr counters // I/O counters (see Stats)
+
+	bmu     sync.Mutex // Guards rbuf, pending, rxt and echo
+	rbuf    []byte     // Received bytes not consumed yet
+	pending string     // Partial line of an interrupted ReadLine
+	rxt     time.Time  // Time the last received bytes were read from the OS
+	echo    []byte     // Written bytes whose echo is expected (see SuppressEcho)
+
+	emu  sync.Mutex // Guards rerr and werr
+	rerr error      // Last read error
+	werr error      // Last write error
+
+	opened time.Time   // Open time
+	tmu    sync.Mutex  // Guards trace settings
+	tw     io.Writer   // Trace writer
+	tfmt   TraceFormat // Trace time stamps format
+
 	//Characters ignored in LineRead
 	LineIgnore string
 	//Characters signaling end of line
 	LineEnd string
+	//Maximum line length in ReadLine (0 = unlimited)
+	LineMax int
+	//Match LineEnd as a whole sequence (Ex. "\r\n") rather than as a set of characters
+	TextMode bool
+	//Drop the echo of written bytes from received data (half duplex buses, see ErrEchoMismatch)
+	SuppressEcho bool
 }
 
 const (
-	PAR_NONE = iota // No parity
-	PAR_EVEN        // Even parity
-	PAR_ODD         // Odd parity
+	PAR_NONE  = iota // No parity
+	PAR_EVEN         // Even parity
+	PAR_ODD          // Odd parity
+	PAR_MARK         // Mark parity (parity bit always 1)
+	PAR_SPACE        // Space parity (parity bit always 0)
 )
 
+// STOP_1_5 selects 1.5 stop bits in SetStopBits and Config, and is reported by StopBits.
+const STOP_1_5 = 15
+
 const (
-	FLUSH_I  = iota // Flush input buffer
-	FLUSH_O         // Flush output buffer
-	FLUSH_IO        // Flush input/output buffers
+	FLUSH_I     = iota // Flush input buffer
+	FLUSH_O            // Flush output buffer (pending output is discarded, not sent)
+	FLUSH_IO           // Flush input/output buffers
+	FLUSH_DRAIN        // Send pending output and wait until it is transmitted (see Drain)
 )
 
-var ErrTimeout = poll.ErrTimeout
-var ErrClosed = poll.ErrClosed
+// Option configures serial at open time.
+type Option func(*Serial) error
+
+// ErrDeviceGone is returned by Open when the device node exists but the device is not present
+// (Ex. unplugged USB adapter). A missing device node returns an os.IsNotExist error.
+var ErrDeviceGone = errors.New("device not present")
+
+// ErrDisconnected is returned by reads and writes when the device went away
+// (Ex. unplugged USB adapter, EIO/ENXIO/ENODEV from the driver). See Reopen.
+var ErrDisconnected = errors.New("device disconnected")
+
+// ErrUnsupported is returned when the port or its driver doesn't support the operation.
+var ErrUnsupported = errors.New("operation not supported")
+
+// ErrParityUnsupported is returned when mark/space parity is requested and the driver lacks it.
+var ErrParityUnsupported = errors.New("mark/space parity not supported")
+
+// ErrTooLong is returned when received data exceeds the requested maximum length.
+var ErrTooLong = errors.New("data too long")
+
+// ErrLineTooLong is returned by ReadLine when a line exceeds LineMax.
+var ErrLineTooLong = errors.New("line too long")
+
+// Default serial settings applied by Open (8N1, no flow control).
+const (
+	DefaultSpeed = 9600 // Baud rate
+	DefaultBits  = 8    // Data bits
+)
 
 // Open opens serial with default params.
 //   Params:
 //     path: Device path (Ex. "/dev/ttyUSB0")
-//	 Default: 9600 8N1, soft/hard, flow controll off.
-func Open(path string) (*Serial, error) {
-	fd, err := open(path)
+//     opts: Options applied after default params.
+//	 Default: 9600 8N1, soft/hard, flow controll off, local mode on.
+// The device is opened with O_NONBLOCK, so open never waits for carrier (DCD) on modem lines.
+// Use WithLocal(false) to honor modem control lines once open, and CarrierDetect to check carrier.
+func Open(path string, opts ...Option) (*Serial, error) {
+	return OpenFlags(path, 0, opts...)
+}
+
+// OpenFlags opens serial like Open, ORing flags (Ex. syscall.O_SYNC) with the mandatory ones
+// (read/write access, O_NOCTTY and O_NONBLOCK).
+// Access mode and file creation flags are rejected.
+func OpenFlags(path string, flags int, opts ...Option) (*Serial, error) {
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR) != 0 {
+		return nil, errors.New("unsupported open flags")
+	}
+	return openAccess(path, flags, ReadWrite, opts...)
+}
+
+// openAccess opens serial with extra open flags and access mode, then applies opts.
+func openAccess(path string, flags int, mode AccessMode, opts ...Option) (*Serial, error) {
+	fd, err := open(path, flags|mode.oflag())
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSerial(fd, path)
 	if err != nil {
 		return nil, err
 	}
-	pfd, err := poll.NewFile(uintptr(fd), path)
+	s.flags, s.mode = flags, mode
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// OpenExclusive opens serial like Open and sets exclusive mode (see SetExclusive)
+// before applying opts.
+func OpenExclusive(path string, opts ...Option) (*Serial, error) {
+	return Open(path, append([]Option{func(s *Serial) error { return s.SetExclusive(true) }}, opts...)...)
+}
+
+// newSerial wraps file descriptor fd and sets default params.
+func newSerial(fd int, name string) (*Serial, error) {
+	pfd, err := newFile(fd, name)
 	if err != nil {
 		return nil, err
 	}
-	s := &Serial{f: pfd, LineIgnore: "\r", LineEnd: "\n"}
+	s := &Serial{LineIgnore: "\r", LineEnd: "\n", opened: time.Now()}
+	s.f.Store(pfd)
 	err = s.init()
 	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	var t Termios
+	if err = s.tcGetAttr(&t); err != nil {
+		s.Close()
 		return nil, err
 	}
+	s.attr = &t
 	return s, nil
 }
 
 // Close closes serial.
 func (s *Serial) Close() error {
-	err := s.f.Close()
+	err := s.file().Close()
 	return err
 }
 
 // Read reads slice from serial.
+// Read performs a single read, returning as soon as some bytes are available,
+// so it may return less than len(b) bytes. Use ReadRecord to read an exact number of bytes.
 func (s *Serial) Read(b []byte) (int, error) {
-	return s.f.Read(b)
+	if err := s.checkRead(b); err != nil {
+		return 0, err
+	}
+	return s.read(b)
+}
+
+func (s *Serial) read(b []byte) (int, error) {
+	return s.readWith(func() (int, error) { return s.readRaw(b) })
+}
+
+// readWith runs raw read function raw, reopening resilient ports on device loss and
+// recording the read error.
+func (s *Serial) readWith(raw func() (int, error)) (int, error) {
+	if s.mode == WriteOnly {
+		return 0, ErrWriteOnly
+	}
+	var n int
+	var err error
+	if s.resil.Load() && s.lost.Load() && s.Reopen() != nil {
+		err = ErrDisconnected
+	} else {
+		n, err = raw()
+		if n == 0 && s.resil.Load() && deviceLost(err) && s.Reopen() == nil {
+			n, err = raw()
+		}
+	}
+	err = disconnected(err)
+	s.ctr.fail(&s.ctr.rerrs, err)
+	s.emu.Lock()
+	s.rerr = err
+	s.emu.Unlock()
+	return n, err
+}
+
+func (s *Serial) readRaw(b []byte) (int, error) {
+	for {
+		n, echo, err := s.readPass(b)
+		if !echo {
+			return n, err
+		}
+		// Only our own echo arrived, wait for more.
+	}
+}
+
+// readPass reads once from buffered bytes or the OS, dropping our own echo.
+// echo is true when only echo arrived (n == 0, err == nil).
+func (s *Serial) readPass(b []byte) (n int, echo bool, err error) {
+	if n := s.readBuffered(b); n > 0 {
+		return n, false, nil
+	}
+	n, err = s.readOS(b)
+	if n == 0 || !s.SuppressEcho {
+		return n, false, err
+	}
+	m, eerr := s.dropEcho(b[:n])
+	if eerr != nil {
+		return 0, false, eerr
+	}
+	return m, m == 0 && err == nil, err
+}
+
+// readOS reads from the OS, applying read limits and translation.
+func (s *Serial) readOS(b []byte) (int, error) {
+	if err := s.armRead(); err != nil {
+		return 0, err
+	}
+	if max := int(s.rmax.Load()); max > 0 && len(b) > max {
+		b = b[:max]
+	}
+	rate := int(s.rrate.Load())
+	if chunk := rate / 100; rate > 0 && len(b) > chunk {
+		if chunk == 0 {
+			chunk = 1
+		}
+		b = b[:chunk]
+	}
+	n, err := s.readFile(b)
+	if n > 0 {
+		s.stamp()
+	}
+	for try := int64(0); n == 0 && errors.Is(err, syscall.EIO) && try < s.eion.Load(); try++ {
+		time.Sleep(time.Duration(s.eiod.Load()))
+		if err = s.armRead(); err == nil {
+			if n, err = s.readFile(b); n > 0 {
+				s.stamp()
+			}
+		}
+	}
+	if rate > 0 {
+		time.Sleep(time.Duration(n) * time.Second / time.Duration(rate))
+	}
+	s.translateRx(b[:n])
+	s.trace('R', b[:n])
+	s.ctr.read.Add(uint64(n)) // Counted when received, not when taken from the read buffer
+	return n, err
+}
+
+// LastReadError returns the error of the last read, nil if it succeeded.
+func (s *Serial) LastReadError() error {
+	s.emu.Lock()
+	defer s.emu.Unlock()
+	return s.rerr
+}
+
+// LastWriteError returns the error of the last write, nil if it succeeded.
+func (s *Serial) LastWriteError() error {
+	s.emu.Lock()
+	defer s.emu.Unlock()
+	return s.werr
+}
+
+// translateRx applies read translation table to received bytes.
+func (s *Serial) translateRx(b []byte) {
+	if tab := s.rxlat.Load(); tab != nil {
+		for i, c := range b {
+			b[i] = tab[c]
+		}
+	}
+}
+
+// readBuffered copies into b bytes from the userspace read buffer.
+func (s *Serial) readBuffered(b []byte) int {
+	s.bmu.Lock()
+	defer s.bmu.Unlock()
+	n := copy(b, s.rbuf)
+	s.rbuf = s.rbuf[n:]
+	return n
+}
+
+// readAhead is the max number of bytes ReadByte requests from the OS at once.
+const readAhead = 256
+
+// buffered returns the number of bytes in the userspace read buffer.
+func (s *Serial) buffered() int {
+	s.bmu.Lock()
+	defer s.bmu.Unlock()
+	return len(s.rbuf)
+}
+
+// unread puts b back in front of the userspace read buffer.
+func (s *Serial) unread(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	s.bmu.Lock()
+	s.rbuf = append(append([]byte(nil), b...), s.rbuf...)
+	s.bmu.Unlock()
+}
+
+// dropInput discards the userspace read buffer.
+// Called whenever received data interpretation changes (speed, framing, translation...)
+// or input is flushed, so bytes decoded with old settings don't leak into next reads.
+func (s *Serial) dropInput() {
+	s.bmu.Lock()
+	s.rbuf = nil
+	s.bmu.Unlock()
 }
 
 // WriteString writes string to serial.
 func (s *Serial) WriteString(str string) (int, error) {
-	return s.f.Write([]byte(str))
+	return s.Write([]byte(str))
 }
 
 // Write writes byte slice to serial.
 func (s *Serial) Write(b []byte) (int, error) {
-	return s.f.Write(b)
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return s.write(b)
+}
+
+// write writes byte slice to serial, s.wmu must be held.
+func (s *Serial) write(b []byte) (int, error) {
+	if tab := s.txlat.Load(); tab != nil {
+		tb := make([]byte, len(b))
+		for i, c := range b {
+			tb[i] = tab[c]
+		}
+		b = tb
+	}
+	return s.writeRaw(b)
+}
+
+// writeRaw writes byte slice to serial as is, bypassing the write translation table,
+// s.wmu must be held.
+func (s *Serial) writeRaw(b []byte) (int, error) {
+	if s.mode == ReadOnly {
+		return 0, ErrReadOnly
+	}
+	s.wn++
+	if err := s.armWrite(); err != nil {
+		return 0, err
+	}
+	n, err := s.file().Write(b)
+	err = disconnected(err)
+	s.trace('W', b[:n])
+	s.ctr.written.Add(uint64(n))
+	if n > 0 && s.SuppressEcho {
+		s.expectEcho(b[:n])
+	}
+	s.ctr.fail(&s.ctr.werrs, err)
+	s.emu.Lock()
+	s.werr = err
+	s.emu.Unlock()
+	return n, err
+}
+
+// WriteFull writes the whole byte slice to serial, retrying short writes.
+// It returns the number of bytes written; on timeout (write deadline or default write timeout)
+// the count of bytes that made it out is returned with ErrTimeout, so the rest can be retried.
+func (s *Serial) WriteFull(b []byte) (int, error) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return s.writeFull(b)
+}
+
+// writeFull writes the whole byte slice to serial, s.wmu must be held.
+// It returns the number of bytes written, on timeout the partial count is returned with ErrTimeout.
+// Writes never block in the kernel, so a peer holding the line stopped (XOFF or CTS low)
+// makes writeFull return ErrTimeout when the write deadline expires.
+func (s *Serial) writeFull(b []byte) (n int, err error) {
+	for n < len(b) && err == nil {
+		var nn int
+		nn, err = s.write(b[n:])
+		n += nn
+	}
+	return
+}
+
+// flowChunk is how many bytes WriteFlowControlled writes between CTS checks.
+const flowChunk = 64
+
+// WriteFlowControlled writes the whole byte slice, pausing while the peer holds CTS low
+// and resuming when it is asserted again, bounded by the write deadline.
+// On timeout the partial count is returned with ErrTimeout.
+func (s *Serial) WriteFlowControlled(b []byte) (n int, err error) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.dmu.Lock()
+	dl := s.writeDeadline()
+	s.dmu.Unlock()
+	for n < len(b) {
+		if err = s.waitCTS(dl); err != nil {
+			return
+		}
+		chunk := b[n:]
+		if len(chunk) > flowChunk {
+			chunk = chunk[:flowChunk]
+		}
+		var nn int
+		nn, err = s.writeFull(chunk)
+		n += nn
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// WritePaced writes b in pieces of chunk bytes, waiting until each piece has been transmitted
+// and then gap before the next one, for slow receivers without flow control
+// (terminal paste delay). Writes and waits honor the write deadline like WriteFull and Drain,
+// a gap ending past it returns ErrTimeout. On error the count of bytes written is returned.
+func (s *Serial) WritePaced(b []byte, chunk int, gap time.Duration) (n int, err error) {
+	if chunk <= 0 {
+		chunk = 1
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.dmu.Lock()
+	dl := s.writeDeadline()
+	s.dmu.Unlock()
+	for n < len(b) {
+		if n > 0 {
+			if !dl.IsZero() && time.Now().Add(gap).After(dl) {
+				return n, ErrTimeout
+			}
+			time.Sleep(gap)
+		}
+		piece := b[n:]
+		if len(piece) > chunk {
+			piece = piece[:chunk]
+		}
+		var nn int
+		nn, err = s.writeFull(piece)
+		n += nn
+		if err != nil {
+			return
+		}
+		if n < len(b) {
+			if err = s.drain(); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// waitCTS polls modem status until CTS is asserted or deadline dl (if not zero) expires.
+func (s *Serial) waitCTS(dl time.Time) error {
+	for {
+		ctr, err := s.getCtrl()
+		if err != nil {
+			return err
+		}
+		if ctr&CTS != 0 {
+			return nil
+		}
+		left := time.Hour
+		if !dl.IsZero() {
+			if left = dl.Sub(time.Now()); left <= 0 {
+				return ErrTimeout
+			}
+		}
+		time.Sleep(pollDelay(5*time.Millisecond, left))
+	}
+}
+
+// WriteAsync queues b for writing and returns immediately.
+// In background the whole slice is written and drained (physically transmitted),
+// then onDone (if not nil) is called with the result.
+// Queued writes are performed in call order. b is copied, so it can be reused after the call.
+func (s *Serial) WriteAsync(b []byte, onDone func(err error)) {
+	buf := append([]byte(nil), b...)
+	done := make(chan struct{})
+	s.amu.Lock()
+	prev := s.atail
+	s.atail = done
+	s.amu.Unlock()
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		s.wmu.Lock()
+		_, err := s.writeFull(buf)
+		if err == nil {
+			err = s.drain()
+		}
+		s.wmu.Unlock()
+		if onDone != nil {
+			onDone(err)
+		}
+	}()
+}
+
+// SetReadTranslation sets a table mapping every received byte (nil disables translation).
+// It applies to all read methods (Read, ReadByte, ReadLine...).
+func (s *Serial) SetReadTranslation(table *[256]byte) {
+	s.rxlat.Store(table)
+	s.dropInput()
+}
+
+// SetWriteTranslation sets a table mapping every transmitted byte (nil disables translation).
+// It applies to all write methods (Write, WriteString, WriteByte...).
+func (s *Serial) SetWriteTranslation(table *[256]byte) {
+	s.txlat.Store(table)
 }
 
 // WriteByte writes one byte to serial.
 func (s *Serial) WriteByte(c byte) error {
-	_, e := s.f.Write([]byte{c})
+	_, e := s.Write([]byte{c})
 	return e
 }
 
+// SetReadMaxSyscall caps how many bytes a single underlying read syscall requests
+// (0 = no cap), for drivers misbehaving with huge read sizes.
+func (s *Serial) SetReadMaxSyscall(n int) {
+	s.rmax.Store(int64(n))
+}
+
+// SetReadRate throttles reads to bytesPerSecond (0 = unlimited), simulating a slow consumer.
+// It's meant for testing backpressure and flow control with OpenPair ports:
+// unread bytes pile up in the kernel buffers and eventually stop the writer.
+func (s *Serial) SetReadRate(bytesPerSecond int) {
+	s.rrate.Store(int64(bytesPerSecond))
+}
+
+// SetEIORecovery makes reads retry up to attempts times, waiting delay before each retry,
+// when the driver returns EIO (transient glitch on flaky USB links). 0 attempts disables it.
+// Other errors, like ENODEV for an unplugged device, are returned at once.
+// When retries are exhausted the read fails with ErrDisconnected.
+func (s *Serial) SetEIORecovery(attempts int, delay time.Duration) {
+	s.eiod.Store(int64(delay))
+	s.eion.Store(int64(attempts))
+}
+
 // ReadByte reads one byte from serial.
+// Bytes received along with it are kept read ahead, so byte loops (ReadLine, WaitForRe...)
+// don't issue one syscall per byte.
+// It never returns without a byte and without error: with VMIN 0 read timing
+// (see SetReadIntervalTimeout) a read getting nothing returns ErrTimeout.
 func (s *Serial) ReadByte() (byte, error) {
-	buf := make([]byte, 1)
-	n, e := s.f.Read(buf)
-	if n == 1 {
-		return buf[0], nil
+	s.bmu.Lock()
+	if len(s.rbuf) > 0 {
+		b := s.rbuf[0]
+		s.rbuf = s.rbuf[1:]
+		s.bmu.Unlock()
+		return b, nil
+	}
+	s.bmu.Unlock()
+	buf := make([]byte, readAhead)
+	for {
+		n, e := s.read(buf)
+		if n > 0 {
+			s.unread(buf[1:n])
+			return buf[0], nil
+		}
+		if e != nil {
+			return 0, e
+		}
+		if ivl := s.ivl.Load(); ivl != nil && ivl.min == 0 {
+			return 0, ErrTimeout // VMIN 0 read: nothing arrived in time
+		}
+		// Spurious empty read, read again.
+	}
+}
+
+// Peek returns the next n bytes without consuming them: following reads (Read, ReadLine...)
+// return them again. It waits for them within the read deadline; if fewer than n bytes
+// arrive in time it returns those with the error (Ex. ErrTimeout), still keeping them buffered.
+func (s *Serial) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errors.New("invalid peek length")
+	}
+	b := make([]byte, n)
+	got, err := s.readFull(b)
+	s.unread(b[:got])
+	return b[:got], err
+}
+
+// TryReadByte reads one byte from serial without blocking.
+// It returns ok == false if no byte is waiting on input buffer.
+func (s *Serial) TryReadByte() (b byte, ok bool, err error) {
+	n, err := s.InpWaiting()
+	if err != nil || n == 0 {
+		return 0, false, err
+	}
+	if b, err = s.ReadByte(); err != nil {
+		return 0, false, err
+	}
+	return b, true, nil
+}
+
+// ReadAvailable reads up to len(b) bytes already waiting on input without blocking.
+// It returns 0, nil at once if nothing is waiting, or if all of it was our own echo
+// (see SuppressEcho).
+func (s *Serial) ReadAvailable(b []byte) (int, error) {
+	n, err := s.InpWaiting()
+	if err != nil || n == 0 || len(b) == 0 {
+		return 0, err
+	}
+	if n < len(b) {
+		b = b[:n]
+	}
+	if err := s.checkRead(b); err != nil {
+		return 0, err
+	}
+	return s.readWith(func() (int, error) {
+		n, _, err := s.readPass(b)
+		return n, err
+	})
+}
+
+// WaitForBytes waits until at least n bytes are waiting to be read (kernel input buffer
+// plus bytes already read ahead), so a following Read gets them at once.
+// It returns ErrTimeout if they don't arrive within timeout.
+func (s *Serial) WaitForBytes(n int, timeout time.Duration) error {
+	dl := time.Now().Add(timeout)
+	ct, err := s.charTime()
+	if err != nil {
+		return err
+	}
+	for {
+		avail, err := s.inpWaiting()
+		if err != nil {
+			return err
+		}
+		avail += s.buffered()
+		if avail >= n {
+			return nil
+		}
+		left := dl.Sub(time.Now())
+		if left <= 0 {
+			return ErrTimeout
+		}
+		time.Sleep(pollDelay(ct*time.Duration(n-avail), left))
 	}
-	return 0, e
 }
 
 // Name returns serial file name.
 func (s *Serial) Name() string {
-	return s.f.Name()
+	return s.file().Name()
 }
 
-// File returns serial os.File struct.
-func (s *Serial) File() *poll.File {
-	return s.f
+// file returns current serial file.
+func (s *Serial) file() *file {
+	return s.f.Load()
 }
 
 // Fd returns serial file descriptor.
 func (s *Serial) Fd() uintptr {
-	return s.f.Fd()
+	return s.file().Fd()
 }
 
 // SetBits sets frame bits (5,6,7,8).
 func (s *Serial) SetBits(bits int) error {
-	return s.setBits(bits)
+	defer s.dropInput()
+	return s.wrapErr("SetBits", s.setBits(bits))
 }
 
 // SetSpeed sets serial speed.
+// Non standard rates (Ex. 250000) are passed to the driver as custom rates (BOTHER on Linux,
+// IOSSIOSPEED on Darwin, the DCB on Windows), an error is returned if the driver can't do them.
+// Errors setting a non standard rate name the nearest standard one (see SupportedSpeeds).
+// Rates no platform can set (Ex. negative) return ErrSpeedUnsupported without touching the port.
 func (s *Serial) SetSpeed(speed int) error {
-	return s.setSpeed(speed)
+	if speed == 0 && s.strict.Load() {
+		return s.wrapErr("SetSpeed", strictError("speed 0 hangs up the line, use HangUp"))
+	}
+	defer s.dropInput()
+	return s.wrapErr("SetSpeed", speedError(speed, s.setSpeed(speed)))
 }
 
 // SetHwFlowCtrl enable or disable Hardware flow control.
 func (s *Serial) SetHwFlowCtrl(hw bool) error {
-	return s.setHwFlowCtrl(hw)
+	if err := s.checkFlowCtrl(func(_, cur bool) (bool, bool) { return hw, cur }); err != nil {
+		return s.wrapErr("SetHwFlowCtrl", err)
+	}
+	return s.wrapErr("SetHwFlowCtrl", s.setHwFlowCtrl(hw))
 }
 
 // SetSwFlowCtrl enable or disable software flow control.
 func (s *Serial) SetSwFlowCtrl(sw bool) error {
-	return s.setSwFlowCtrl(sw)
+	if err := s.checkFlowCtrl(func(cur, _ bool) (bool, bool) { return cur, sw }); err != nil {
+		return s.wrapErr("SetSwFlowCtrl", err)
+	}
+	return s.wrapErr("SetSwFlowCtrl", s.setSwFlowCtrl(sw))
 }
 
-// SetStopBits sets stop bits, valid values are 1 or 2.
+// SetStopBits sets stop bits, valid values are 1, 2 or STOP_1_5.
+// STOP_1_5 (1.5 stop bits) is only valid with 5 data bits, set them first.
 func (s *Serial) SetStopBits(stop int) error {
-	switch stop {
-	case 1:
-		return s.setStopBits2(false)
-	case 2:
-		return s.setStopBits2(true)
-	default:
-		return errors.New("Invalid stop bits number")
-	}
+	defer s.dropInput()
+	return s.wrapErr("SetStopBits", s.setStopBits(stop))
 }
 
 // SetParity sets parity mode:
 //   PAR_NONE
 //   PAR_EVEN
 //   PAR_ODD
+//   PAR_MARK  (ErrParityUnsupported if the driver lacks it)
+//   PAR_SPACE (ErrParityUnsupported if the driver lacks it)
 func (s *Serial) SetParity(mode int) error {
-	return s.setParity(mode)
+	defer s.dropInput()
+	return s.wrapErr("SetParity", s.setParity(mode))
 }
 
 // SetLocal sets local mode. In local mode, modem control lines are ignored.
 func (s *Serial) SetLocal(local bool) error {
-	return s.setLocal(local)
+	return s.wrapErr("SetLocal", s.setLocal(local))
+}
+
+// WithLocal sets local mode (see SetLocal) when opening.
+func WithLocal(local bool) Option {
+	return func(s *Serial) error {
+		return s.SetLocal(local)
+	}
+}
+
+// SetCanonicalWithEditing enables canonical (line) mode with echo and kernel line editing,
+// erase deletes last character and kill deletes the whole line (Ex. 0x7f and 0x15).
+// Reads then return complete edited lines, CR is translated to LF on input.
+func (s *Serial) SetCanonicalWithEditing(erase, kill byte) error {
+	defer s.dropInput()
+	return s.wrapErr("SetCanonicalWithEditing", s.setCanonical(erase, kill))
+}
+
+// SetRaw switches between raw mode (raw == true, the default after open, like cfmakeraw)
+// and cooked canonical mode (Ex. talking to a login shell): line buffered input with
+// kernel editing, echo, CR to LF input translation, LF to CRLF output translation and
+// signal characters. Data bits, parity and flow control are left as they are.
+// In cooked mode reads return whole lines ended by LF, so ReadLine keeps working with the
+// default LineEnd; echo sends received characters back to the peer.
+func (s *Serial) SetRaw(raw bool) error {
+	defer s.dropInput()
+	return s.wrapErr("SetRaw", s.setRaw(raw))
+}
+
+// ControlFlagsInfo is a platform independent view of serial control flags.
+type ControlFlagsInfo struct {
+	DataBits      int  // 5 to 8
+	StopBits      int  // 1, 2 or STOP_1_5
+	Parity        int  // PAR_NONE, PAR_EVEN, PAR_ODD, PAR_MARK or PAR_SPACE
+	HwFlow        bool // RTS/CTS flow control
+	Local         bool // Modem control lines ignored
+	HangupOnClose bool // DTR/RTS dropped on close
+}
+
+// ControlFlags returns control flags decoded from current serial attributes.
+func (s *Serial) ControlFlags() (ControlFlagsInfo, error) {
+	return s.controlFlags()
+}
+
+// Speed returns current serial speed, as applied by the driver.
+func (s *Serial) Speed() (int, error) {
+	return s.speed()
+}
+
+// Bits returns current number of data bits.
+func (s *Serial) Bits() (int, error) {
+	cf, err := s.controlFlags()
+	return cf.DataBits, err
+}
+
+// Parity returns current parity mode (PAR_NONE, PAR_EVEN, PAR_ODD, PAR_MARK or PAR_SPACE).
+func (s *Serial) Parity() (int, error) {
+	cf, err := s.controlFlags()
+	return cf.Parity, err
+}
+
+// StopBits returns current number of stop bits (1, 2 or STOP_1_5).
+func (s *Serial) StopBits() (int, error) {
+	cf, err := s.controlFlags()
+	return cf.StopBits, err
+}
+
+// String describes serial state for logging (Ex. "/dev/ttyUSB0 115200 8N1 hw-flow=off local=on").
+// If attributes can't be read it returns "<path> (attrs unavailable)".
+func (s *Serial) String() string {
+	speed, err := s.speed()
+	if err != nil {
+		return s.Name() + " (attrs unavailable)"
+	}
+	cf, err := s.controlFlags()
+	if err != nil {
+		return s.Name() + " (attrs unavailable)"
+	}
+	p, ok := parityChars[cf.Parity]
+	if !ok {
+		p = '?'
+	}
+	return fmt.Sprintf("%s %d %d%c%s hw-flow=%s local=%s", s.Name(), speed, cf.DataBits, p,
+		stopBitsString(cf.StopBits), onOff(cf.HwFlow), onOff(cf.Local))
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
 }
 
 // GetAttr sets Termios structure from serial attributes.
@@ -157,17 +871,65 @@ func (s *Serial) GetAttr(attr *Termios) error {
 
 // SetAttr sets serial attributes from Termios structure.
 func (s *Serial) SetAttr(attr *Termios) error {
-	return s.tcSetAttr(attr)
+	defer s.dropInput()
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	if err := s.tcSetAttr(attr); err != nil {
+		return s.wrapErr("SetAttr", err)
+	}
+	s.attr = attr.Clone()
+	return nil
+}
+
+// SetAttrDrain sets serial attributes from Termios structure once all output written so far
+// has been transmitted (TCSADRAIN semantics), so queued data goes out with the old settings.
+// Writes are held back until the change is done. Draining is bounded like Drain;
+// on ErrTimeout attributes are left unchanged.
+func (s *Serial) SetAttrDrain(attr *Termios) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	if err := s.drainPending(); err != nil {
+		return err
+	}
+	return s.SetAttr(attr)
 }
 
-// SetHub sets hangup mode (false -> don't reset DTR/RTS on exit).
+// SetHup sets hangup on close mode (termios HUPCL): when true (the default after open)
+// DTR and RTS are dropped when the last descriptor of the port is closed, which resets
+// many boards; false keeps them as they are.
+// The setting belongs to the port, not to serial: it stays after Close and applies to
+// later opens too, until changed.
 func (s *Serial) SetHup(hup bool) error {
-	return s.setHup(hup)
+	return s.wrapErr("SetHup", s.setHup(hup))
 }
 
-// InpWaiting returns number of bytes waiting on input buffer.
+// Hup reports whether hangup on close (HUPCL) is set (see SetHup).
+func (s *Serial) Hup() (bool, error) {
+	cf, err := s.controlFlags()
+	return cf.HangupOnClose, err
+}
+
+// SetExclusive sets exclusive mode: while set, further opens of the port fail with EBUSY
+// (except for root). Descriptors already open aren't affected.
+// The kernel releases the lock when the last descriptor of the port is closed.
+func (s *Serial) SetExclusive(excl bool) error {
+	return s.wrapErr("SetExclusive", s.setExclusive(excl))
+}
+
+// InpWaiting returns number of bytes waiting on input buffer,
+// including bytes already read ahead by ReadByte (see Buffered).
 func (s *Serial) InpWaiting() (int, error) {
-	return s.inpWaiting()
+	n, err := s.inpWaiting()
+	if err != nil {
+		return 0, err
+	}
+	return n + s.buffered(), nil
+}
+
+// Buffered returns the number of bytes already read from the OS and waiting in
+// userspace to be returned by next reads.
+func (s *Serial) Buffered() int {
+	return s.buffered()
 }
 
 // OutWaiting returns number of bytes waiting on output buffer.
@@ -177,79 +939,761 @@ func (s *Serial) OutWaiting() (int, error) {
 
 // SetDeadline sets read/write deadline time
 func (s *Serial) SetDeadline(t time.Time) error {
-	return s.f.SetDeadline(t)
+	if err := s.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return s.SetWriteDeadline(t)
 }
 
-// SetReadDeadline sets read deadline time
+// SetReadDeadline sets read deadline time (zero time clears it).
+// An explicit deadline overrides the default read timeout.
 func (s *Serial) SetReadDeadline(t time.Time) error {
-	return s.f.SetReadDeadline(t)
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	s.rdl = t
+	s.frdl = t
+	return s.file().SetReadDeadline(t)
 }
 
-// SetWriteDeadline sets write deadline time
+// SetWriteDeadline sets write deadline time (zero time clears it).
+// An explicit deadline overrides the default write timeout.
 func (s *Serial) SetWriteDeadline(t time.Time) error {
-	return s.f.SetWriteDeadline(t)
+	s.dmu.Lock()
+	defer s.dmu.Unlock()
+	s.wdl = t
+	s.fwdl = t
+	return s.file().SetWriteDeadline(t)
 }
 
 // Flush buffers selected by mode:
-//   FLUSH_I  input buffer
-//   FLUSH_O  output buffer
-//   FLUSH_IO input and output buffers
+//   FLUSH_I     input buffer
+//   FLUSH_O     output buffer
+//   FLUSH_IO    input and output buffers
+//   FLUSH_DRAIN output buffer, by transmitting it
+// Flushing input also discards bytes already read ahead into userspace (ReadByte, Peek...)
+// and the pending partial line of an interrupted ReadLine, so next reads start fresh.
+// WARNING: FLUSH_O and FLUSH_IO *discard* pending output, like tcflush does; data is never sent.
+// Use FLUSH_DRAIN (or Drain) to make sure written data goes out the wire.
 func (s *Serial) Flush(mode int) error {
+	if mode == FLUSH_DRAIN {
+		return s.Drain()
+	}
+	if mode == FLUSH_I || mode == FLUSH_IO {
+		s.dropInput()
+		s.Pending()
+	}
 	return s.flush(mode)
 }
 
+// DiscardInput discards all received data not read yet, in kernel and userspace buffers
+// (Ex. stale bytes before sending a command). Same as Flush(FLUSH_I).
+func (s *Serial) DiscardInput() error {
+	return s.Flush(FLUSH_I)
+}
+
+// WaitQuiet discards received data until no new bytes have arrived for quiet
+// (Ex. garbage sent by a device while powering up), leaving input clean.
+// It returns ErrTimeout if the line isn't quiet within max.
+func (s *Serial) WaitQuiet(quiet, max time.Duration) error {
+	start := time.Now()
+	dl := start.Add(max)
+	if err := s.DiscardInput(); err != nil {
+		return err
+	}
+	last := start
+	for {
+		n, err := s.inpWaiting()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if n > 0 {
+			if err := s.DiscardInput(); err != nil {
+				return err
+			}
+			last = now
+		} else if now.Sub(last) >= quiet {
+			return nil
+		}
+		left := dl.Sub(now)
+		if left <= 0 {
+			return ErrTimeout
+		}
+		time.Sleep(pollDelay(last.Add(quiet).Sub(now), left))
+	}
+}
+
+// FlushCount flushes buffers selected by mode like Flush and returns how many bytes were
+// discarded (Ex. stale input before sending a command). Nothing is done when selected buffers
+// are empty. FLUSH_DRAIN discards nothing, it drains output and returns 0.
+func (s *Serial) FlushCount(mode int) (int, error) {
+	if mode == FLUSH_DRAIN {
+		return 0, s.Drain()
+	}
+	n := 0
+	if mode == FLUSH_I || mode == FLUSH_IO {
+		in, err := s.InpWaiting()
+		if err != nil {
+			return 0, err
+		}
+		n += in
+	}
+	if mode == FLUSH_O || mode == FLUSH_IO {
+		out, err := s.outWaiting()
+		if err != nil {
+			return 0, err
+		}
+		n += out
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.Flush(mode)
+}
+
+// AbortOutput discards data waiting on output buffer and returns how many bytes were discarded.
+func (s *Serial) AbortOutput() (discarded int, err error) {
+	if discarded, err = s.outWaiting(); err != nil {
+		return 0, err
+	}
+	if err = s.flush(FLUSH_O); err != nil {
+		return 0, err
+	}
+	return discarded, nil
+}
+
 // SetCtrlBit sets level of modem control signal (DTR, RTS, ...)
 func (s *Serial) SetCtrlBit(ctr int, level bool) error {
-	return s.setCtrlBit(ctr, level)
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	return s.wrapErr("SetCtrlBit", s.setCtrlBit(ctr, level))
+}
+
+// ModemStatus holds the level of modem control lines.
+type ModemStatus struct {
+	CTS bool // Clear to send
+	DSR bool // Data set ready
+	DCD bool // Data carrier detect
+	RI  bool // Ring indicator
+	DTR bool // Data terminal ready
+	RTS bool // Request to send
+	Raw int  // Modem control bits as returned by the driver
+}
+
+// GetStatus gets modem control lines levels.
+func (s *Serial) GetStatus() (ModemStatus, error) {
+	ctr, err := s.getCtrl()
+	if err != nil {
+		return ModemStatus{}, err
+	}
+	return ModemStatus{
+		CTS: ctr&CTS != 0,
+		DSR: ctr&DSR != 0,
+		DCD: ctr&DCD != 0,
+		RI:  ctr&RI != 0,
+		DTR: ctr&DTR != 0,
+		RTS: ctr&RTS != 0,
+		Raw: ctr,
+	}, nil
+}
+
+// CarrierDetect reports whether the DCD line is asserted (Ex. modem connected to a remote peer).
+// Unlike an open failure, a false result with nil error means the port is there but has no carrier.
+func (s *Serial) CarrierDetect() (bool, error) {
+	st, err := s.GetStatus()
+	return st.DCD, err
 }
 
 // GetCtrl gets modem control bits
 func (s *Serial) GetCtrl() (int, error) {
-	return s.getCtrl()
+	st, err := s.GetStatus()
+	return st.Raw, err
+}
+
+// WaitCtrlChange waits until one of the modem status lines in mask (CTS, DSR, CAR, RNG)
+// changes and returns the new modem control bits.
+// It's bounded by the read deadline (explicit deadline or default read timeout),
+// returning ErrTimeout when it expires with no change.
+// Without deadline it blocks in the kernel (TIOCMIWAIT) when the driver supports it,
+// otherwise lines are polled.
+func (s *Serial) WaitCtrlChange(mask int) (int, error) {
+	s.dmu.Lock()
+	dl := s.readDeadline()
+	s.dmu.Unlock()
+	if dl.IsZero() {
+		if err := s.waitCtrl(mask); err != ErrUnsupported {
+			if err != nil {
+				return 0, err
+			}
+			return s.getCtrl()
+		}
+	}
+	count, counted := s.ctrlCount(mask)
+	prev, err := s.getCtrl()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		left := time.Hour
+		if !dl.IsZero() {
+			if left = time.Until(dl); left <= 0 {
+				return prev, ErrTimeout
+			}
+		}
+		time.Sleep(pollDelay(5*time.Millisecond, left))
+		ctr, err := s.getCtrl()
+		if err != nil {
+			return 0, err
+		}
+		if ctr&mask != prev&mask {
+			return ctr, nil
+		}
+		// Counters catch pulses shorter than the poll interval.
+		if n, ok := s.ctrlCount(mask); counted && ok && n != count {
+			return ctr, nil
+		}
+	}
+}
+
+// SignalSnapshot returns the level of every queryable RS-232 line, keyed by its name
+// ("DTR", "RTS", "CTS", "DSR", "DCD", "RI"). TD, RD and GND can't be queried and are omitted.
+func (s *Serial) SignalSnapshot() (map[string]bool, error) {
+	ctr, err := s.getCtrl()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]bool{
+		"DTR": ctr&DTR != 0,
+		"RTS": ctr&RTS != 0,
+		"CTS": ctr&CTS != 0,
+		"DSR": ctr&DSR != 0,
+		"DCD": ctr&CAR != 0,
+		"RI":  ctr&RNG != 0,
+	}, nil
+}
+
+// TestControlLine checks whether output control line ctl (DTR or RTS) responds,
+// toggling it and reading back the modem control bits, then restoring its level.
+// This is a best effort check: it returns false when the read back level doesn't change
+// (the driver doesn't reflect output lines or the adapter lacks the line),
+// and ErrUnsupported when control lines can't be accessed at all.
+func (s *Serial) TestControlLine(ctl int) (bool, error) {
+	if ctl != DTR && ctl != RTS {
+		return false, errors.New("invalid control line")
+	}
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	orig, err := s.getCtrl()
+	if err != nil {
+		return false, ErrUnsupported
+	}
+	level := orig&ctl != 0
+	if err := s.setCtrlBit(ctl, !level); err != nil {
+		return false, ErrUnsupported
+	}
+	ctr, err := s.getCtrl()
+	if rerr := s.setCtrlBit(ctl, level); err == nil {
+		err = rerr
+	}
+	if err != nil {
+		return false, err
+	}
+	return (ctr&ctl != 0) != level, nil
 }
 
 // SetCtrl sets modem control bits
 func (s *Serial) SetCtrl(ctr int) error {
-	return s.setCtrl(ctr)
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	return s.wrapErr("SetCtrl", s.setCtrl(ctr))
+}
+
+// MakeControllingTerminal makes serial the controlling terminal of the calling process,
+// starting a new session (setsid) and acquiring the port with TIOCSCTTY.
+// Prerequisites and failure modes:
+//   - The process must not be a process group leader, setsid fails with EPERM otherwise
+//     (Ex. a program started from an interactive shell); fork a child first.
+//   - The port must not be the controlling terminal of another session, EPERM otherwise.
+//   - The whole process moves to the new session, losing its previous controlling terminal.
+// To spawn a login shell on the port, prefer exec.Cmd with SysProcAttr Setsid and Setctty.
+func (s *Serial) MakeControllingTerminal() error {
+	return s.makeControllingTerminal()
+}
+
+// readFull reads until b is full or an error occurs.
+// It returns the number of bytes read, on timeout the partial count is returned with ErrTimeout.
+func (s *Serial) readFull(b []byte) (n int, err error) {
+	for n < len(b) && err == nil {
+		var nn int
+		nn, err = s.Read(b[n:])
+		n += nn
+	}
+	return
+}
+
+// charTime returns the time needed to transmit one character (start, data, parity and stop bits)
+// at current serial settings.
+func (s *Serial) charTime() (time.Duration, error) {
+	speed, nbits, err := s.frameBits()
+	if err != nil {
+		return 0, err
+	}
+	if speed == 0 {
+		return 0, errors.New("serial speed is zero")
+	}
+	return time.Duration(nbits) * time.Second / time.Duration(speed), nil
+}
+
+// drain waits until output buffer has been transmitted.
+// When a write deadline is in effect, output buffer is polled instead of blocking in tcdrain,
+// so a stopped line (XOFF or CTS low) makes drain return ErrTimeout after the deadline.
+func (s *Serial) drain() error {
+	s.dmu.Lock()
+	dl := s.writeDeadline()
+	s.dmu.Unlock()
+	if dl.IsZero() {
+		return s.tcDrain()
+	}
+	return s.drainUntil(dl)
+}
+
+// SendBreak drains output and then holds the TX line in break state for duration d
+// (0 = standard tcsendbreak duration, between 0.25 and 0.5 seconds).
+// If the write deadline expires first, the break is ended at the deadline and ErrTimeout is returned.
+// The line is always returned to normal state before returning.
+func (s *Serial) SendBreak(d time.Duration) (err error) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	if err = s.drain(); err != nil {
+		return err
+	}
+	if d == 0 {
+		return s.tcSendBreak()
+	}
+	s.dmu.Lock()
+	dl := s.writeDeadline()
+	s.dmu.Unlock()
+	if !dl.IsZero() {
+		if left := time.Until(dl); left < d {
+			d, err = left, ErrTimeout
+		}
+	}
+	if e := s.setBreak(true); e != nil {
+		return e
+	}
+	defer func() {
+		if e := s.setBreak(false); e != nil {
+			err = e
+		}
+	}()
+	time.Sleep(d)
+	return err
+}
+
+// pollDelay returns how long to sleep between polls of buffer state, given the
+// expected time d until the condition holds and the time left until the deadline.
+func pollDelay(d, left time.Duration) time.Duration {
+	if d < time.Millisecond {
+		d = time.Millisecond
+	}
+	if d > 20*time.Millisecond {
+		d = 20 * time.Millisecond
+	}
+	if d > left {
+		d = left
+	}
+	return d
+}
+
+// Drain waits until all output written so far has been physically transmitted.
+// It's bounded by the write deadline (explicit deadline or default write timeout),
+// returning ErrTimeout when it expires first. With empty output buffer it returns at once.
+func (s *Serial) Drain() error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return s.drainPending()
+}
+
+// drainPending waits for pending output like Drain. Caller must hold wmu.
+func (s *Serial) drainPending() error {
+	if n, err := s.outWaiting(); err == nil && n == 0 {
+		if empty, ok := s.txEmpty(); empty || !ok {
+			return nil
+		}
+	}
+	return s.drain()
+}
+
+// DrainTimeout waits until output buffer has been transmitted, up to d.
+// Unlike a blocking tcdrain, it returns ErrTimeout when output isn't done in time
+// (Ex. stuck flow control), leaving pending output untouched.
+func (s *Serial) DrainTimeout(d time.Duration) error {
+	return s.drainUntil(time.Now().Add(d))
+}
+
+// drainUntil polls output buffer (and transmitter state when the driver reports it)
+// until it is empty or deadline dl expires.
+func (s *Serial) drainUntil(dl time.Time) error {
+	ct, err := s.charTime()
+	if err != nil {
+		return err
+	}
+	for {
+		n, err := s.outWaiting()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			if empty, ok := s.txEmpty(); empty || !ok {
+				return nil
+			}
+		}
+		left := dl.Sub(time.Now())
+		if left <= 0 {
+			return ErrTimeout
+		}
+		time.Sleep(pollDelay(ct*time.Duration(n+1), left))
+	}
+}
+
+// SetInterFrameGap sets the silence time WriteFrame guarantees after each frame.
+// A zero duration (the default) means 3.5 character times at current serial settings.
+func (s *Serial) SetInterFrameGap(d time.Duration) {
+	s.wmu.Lock()
+	s.ifg = d
+	s.wmu.Unlock()
+}
+
+// WriteFrame writes the whole frame, waits until it has been transmitted and
+// then keeps the line silent for the inter frame gap (see SetInterFrameGap).
+func (s *Serial) WriteFrame(b []byte) (int, error) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	gap := s.ifg
+	if gap == 0 {
+		ct, err := s.charTime()
+		if err != nil {
+			return 0, err
+		}
+		gap = ct * 7 / 2
+	}
+	n, err := s.writeFull(b)
+	if err != nil {
+		return n, err
+	}
+	if err := s.drain(); err != nil {
+		return n, err
+	}
+	time.Sleep(gap)
+	return n, nil
+}
+
+// ReadFrame reads a frame delimited by line silence: it waits for the first byte (honoring
+// read deadline) and then accumulates bytes until no new byte arrives for idle or max bytes
+// (0 = unlimited) are read. Bytes after max are left for next reads.
+// On error before the first byte the error is returned; once a frame started, the frame read
+// so far is returned when the read deadline expires.
+func (s *Serial) ReadFrame(idle time.Duration, max int) ([]byte, error) {
+	buf := make([]byte, 256)
+	if max > 0 && max < len(buf) {
+		buf = buf[:max]
+	}
+	n, err := s.Read(buf)
+	if n == 0 {
+		return nil, err
+	}
+	frame := append([]byte(nil), buf[:n]...)
+	for max <= 0 || len(frame) < max {
+		chunk := buf
+		if max > 0 && max-len(frame) < len(chunk) {
+			chunk = chunk[:max-len(frame)]
+		}
+		op := s.beginRead(idle)
+		n, err = s.Read(chunk)
+		s.endRead(op)
+		frame = append(frame, chunk[:n]...)
+		if err == ErrTimeout {
+			break
+		}
+		if err != nil {
+			return frame, err
+		}
+	}
+	return frame, nil
 }
 
 // ReadLine reads text line.
 // Serial.LineIgnore field has characters to be ignored (by default "\r").
 // Serial.LineEnd field has end of line characters (by default "\n").
-func (s *Serial) ReadLine() (res string, err error) {
-	var b byte
+// With Serial.TextMode set, LineEnd is instead a multi-byte terminator matched as a whole
+// (Ex. "\r\n", so a lone "\r" or "\n" doesn't end the line) and its bytes are never ignored.
+// If reading fails (Ex. timeout) before end of line, it returns "" and the error.
+// Bytes read up to then are consumed from the OS buffer but kept as pending partial line:
+// next ReadLine resumes with them, or Pending takes them out.
+// When Serial.LineMax is set and the line exceeds it, ReadLine returns the first LineMax bytes
+// and ErrLineTooLong; next ReadLine goes on with the rest of the line.
+func (s *Serial) ReadLine() (string, error) {
+	b, err := s.ReadLineBytes()
+	return string(b), err
+}
+
+// ReadLineBytes reads text line like ReadLine, returning it as a byte slice.
+// Bytes are stored as received, without string conversion on each byte.
+func (s *Serial) ReadLineBytes() ([]byte, error) {
+	res, _, err := s.readLine()
+	return res, err
+}
+
+// ReadFields reads text line like ReadLine, trims surrounding white space and splits it
+// at sep (Ex. "TEMP,23.5,OK" -> ["TEMP" "23.5" "OK"]). Empty lines return an empty slice
+// without error.
+func (s *Serial) ReadFields(sep string) ([]string, error) {
+	line, err := s.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return []string{}, nil
+	}
+	return strings.Split(line, sep), nil
+}
+
+// ReadLineRaw reads text line like ReadLine, also returning how many bytes it consumed
+// from serial, ignored characters and line terminator included.
+// On error consumed is still reported, the partial line being kept as pending (see ReadLine).
+func (s *Serial) ReadLineRaw() (line string, consumed int, err error) {
+	var res []byte
+	res, consumed, err = s.readLine()
+	return string(res), consumed, err
+}
+
+// ReadLineWithin reads text line like ReadLine, waiting up to d from the call (the read
+// deadline still applies when earlier). The port deadline and default timeout are left
+// untouched, so later reads aren't affected. On timeout the partial line is kept as pending.
+func (s *Serial) ReadLineWithin(d time.Duration) (string, error) {
+	defer s.endRead(s.beginRead(d))
+	return s.ReadLine()
+}
+
+// readLine reads text line (see ReadLine), returning it along with the number
+// of bytes consumed from serial.
+func (s *Serial) readLine() (res []byte, n int, err error) {
+	s.bmu.Lock()
+	res = []byte(s.pending)
+	s.pending = ""
+	s.bmu.Unlock()
+	unread := func(b []byte) {
+		s.unread(b)
+		if n -= len(b); n < 0 {
+			n = 0 // Resumed from pending line
+		}
+	}
 	for {
+		var b byte
 		if b, err = s.ReadByte(); err != nil {
-			return
+			if len(res) > 0 {
+				s.bmu.Lock()
+				s.pending = string(res)
+				s.bmu.Unlock()
+			}
+			return nil, n, err
 		}
-		ch := string(b)
-		if strings.Contains(s.LineIgnore, ch) {
+		n++
+		if inSet(b, s.lineIgnore()) {
 			continue
 		}
-		if strings.Contains(s.LineEnd, ch) {
-			break
+		if s.TextMode && s.LineEnd != "" {
+			res = append(res, b)
+			if bytes.HasSuffix(res, []byte(s.LineEnd)) {
+				return res[:len(res)-len(s.LineEnd)], n, nil
+			}
+			// Last bytes may still be the start of a terminator.
+			if s.LineMax > 0 && len(res) >= s.LineMax+len(s.LineEnd) {
+				unread(res[s.LineMax:])
+				return res[:s.LineMax], n, ErrLineTooLong
+			}
+			continue
+		}
+		if inSet(b, s.LineEnd) {
+			return res, n, nil
+		}
+		if s.LineMax > 0 && len(res) >= s.LineMax {
+			unread([]byte{b})
+			return res, n, ErrLineTooLong
+		}
+		res = append(res, b)
+	}
+}
+
+// AutoDetectLineEnding reads until a line terminator arrives (within timeout) and sets
+// LineEnd and LineIgnore for the inferred convention:
+//   "\r\n" -> LineEnd "\n", LineIgnore "\r"
+//   "\n"   -> LineEnd "\n", LineIgnore ""
+//   "\r"   -> LineEnd "\r", LineIgnore ""
+// Bytes read while sniffing are kept, so next ReadLine returns the first line.
+func (s *Serial) AutoDetectLineEnding(timeout time.Duration) error {
+	var acc []byte
+	defer func() { s.unread(acc) }()
+	defer s.endRead(s.beginRead(timeout))
+	for {
+		b, err := s.ReadByte()
+		if err != nil {
+			return err
+		}
+		acc = append(acc, b)
+		switch b {
+		case '\n':
+			s.LineEnd, s.LineIgnore = "\n", ""
+			return nil
+		case '\r':
+			// A LF following CR must arrive within a few character times.
+			wait := 10 * time.Millisecond
+			if ct, err := s.charTime(); err == nil && ct*10 > wait {
+				wait = ct * 10
+			}
+			next := s.beginRead(wait)
+			b, err := s.ReadByte()
+			s.endRead(next)
+			if err == nil {
+				acc = append(acc, b)
+			}
+			if err == nil && b == '\n' {
+				s.LineEnd, s.LineIgnore = "\n", "\r"
+			} else {
+				s.LineEnd, s.LineIgnore = "\r", ""
+			}
+			if err != nil && err != ErrTimeout {
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+// ReadUntilRe reads raw bytes until re matches them, up to max bytes (0 = unlimited) and
+// within timeout (no timeout if timeout <= 0).
+// It returns received bytes up to the end of the match, bytes after it are kept for next reads.
+// On timeout, I/O error or when max bytes are read without a match (ErrTooLong),
+// bytes read so far are returned along with the error.
+func (s *Serial) ReadUntilRe(re *regexp.Regexp, max int, timeout time.Duration) ([]byte, error) {
+	defer s.endRead(s.beginRead(timeout))
+	var acc []byte
+	buf := make([]byte, 256)
+	for {
+		if max > 0 && len(acc) >= max {
+			return acc, ErrTooLong
+		}
+		chunk := buf
+		if max > 0 && max-len(acc) < len(chunk) {
+			chunk = chunk[:max-len(acc)]
+		}
+		n, err := s.Read(chunk)
+		acc = append(acc, chunk[:n]...)
+		if loc := re.FindIndex(acc); loc != nil {
+			s.unread(acc[loc[1]:])
+			return acc[:loc[1]], nil
+		}
+		if err != nil {
+			return acc, err
+		}
+	}
+}
+
+// ReadUntil reads raw bytes until delim is received (it's included in the result),
+// up to max bytes (0 = unlimited), honoring read deadline.
+// On timeout, I/O error or when max bytes are read without delim (ErrTooLong),
+// bytes read so far are returned along with the error.
+func (s *Serial) ReadUntil(delim byte, max int) ([]byte, error) {
+	var acc []byte
+	for {
+		if max > 0 && len(acc) >= max {
+			return acc, ErrTooLong
+		}
+		b, err := s.ReadByte()
+		if err != nil {
+			return acc, err
+		}
+		acc = append(acc, b)
+		if b == delim {
+			return acc, nil
 		}
-		res += ch
 	}
-	return
+}
+
+// Pending returns (and forgets) the partial line accumulated by the last ReadLine
+// interrupted by timeout, Close or I/O error, so it can be logged on shutdown
+// or handled without waiting for the end of line.
+func (s *Serial) Pending() string {
+	s.bmu.Lock()
+	defer s.bmu.Unlock()
+	p := s.pending
+	s.pending = ""
+	return p
 }
 
 // WaitForRe reads lines from serial and waits for line matching one regular expresion from rexp slice.
 // It returns the index of rexp slice matching text line, text line itself and error != nil on timeout or I/O error.
+// Invalid expressions are reported before reading anything.
 func (s *Serial) WaitForRe(rexp []string) (int, string, error) {
-	var match string
-	var err error
+	res, err := compileAll(rexp)
+	if err != nil {
+		return -1, "", err
+	}
+	return s.WaitForReCompiled(res)
+}
+
+// WaitForReTimeout works like WaitForRe within an overall time budget: it returns ErrTimeout
+// once total has elapsed, however many lines (matching or partial) arrived meanwhile.
+// An earlier explicit read deadline still applies.
+func (s *Serial) WaitForReTimeout(rexp []string, total time.Duration) (int, string, error) {
+	res, err := compileAll(rexp)
+	if err != nil {
+		return -1, "", err
+	}
+	defer s.endRead(s.beginRead(total))
+	return s.WaitForReCompiled(res)
+}
+
+// Command sends a request and waits for its reply: it discards stale input (see DiscardInput)
+// so old replies aren't matched, writes cmd as is (include the line terminator, Ex. "ATI\r")
+// and waits for a line matching one of replyRe within timeout, like WaitForReTimeout.
+// Invalid expressions are reported before sending anything.
+func (s *Serial) Command(cmd string, replyRe []string, timeout time.Duration) (int, string, error) {
+	res, err := compileAll(replyRe)
+	if err != nil {
+		return -1, "", err
+	}
+	defer s.endRead(s.beginRead(timeout))
+	if err := s.DiscardInput(); err != nil {
+		return -1, "", err
+	}
+	if _, err := s.WriteFull([]byte(cmd)); err != nil {
+		return -1, "", err
+	}
+	return s.WaitForReCompiled(res)
+}
 
+// compileAll compiles regular expressions rexp.
+func compileAll(rexp []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, len(rexp))
+	for i, re := range rexp {
+		var err error
+		if res[i], err = regexp.Compile(re); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// WaitForReCompiled works like WaitForRe with already compiled regular expressions.
+func (s *Serial) WaitForReCompiled(res []*regexp.Regexp) (int, string, error) {
 	for {
-		if match, err = s.ReadLine(); err != nil {
+		match, err := s.ReadLine()
+		if err != nil {
 			return -1, "", err
 		}
-		for i, re := range rexp {
-			ok, err := regexp.MatchString(re, match)
-			if err != nil {
-				return -1, "", err
-			}
-			if ok {
+		for i, re := range res {
+			if re.MatchString(match) {
 				return i, match, nil
 			}
 		}
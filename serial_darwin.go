@@ -0,0 +1,606 @@
+package serial
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+type Termios syscall.Termios
+
+var baud = map[int]uint64{
+	0:      syscall.B0,
+	50:     syscall.B50,
+	75:     syscall.B75,
+	110:    syscall.B110,
+	134:    syscall.B134,
+	150:    syscall.B150,
+	200:    syscall.B200,
+	300:    syscall.B300,
+	600:    syscall.B600,
+	1200:   syscall.B1200,
+	1800:   syscall.B1800,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	7200:   syscall.B7200,
+	9600:   syscall.B9600,
+	14400:  syscall.B14400,
+	19200:  syscall.B19200,
+	28800:  syscall.B28800,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	76800:  syscall.B76800,
+	115200: syscall.B115200,
+	230400: syscall.B230400,
+}
+
+var bits = map[int]uint64{
+	5: syscall.CS5,
+	6: syscall.CS6,
+	7: syscall.CS7,
+	8: syscall.CS8,
+}
+
+// Constants not defined in syscall module
+const (
+	crtscts     = 0x00030000 // CCTS_OFLOW | CRTS_IFLOW
+	fionread    = 0x4004667F
+	iossiospeed = 0x80085402
+	fread       = 0x01
+	fwrite      = 0x02
+)
+
+// Constants for modem control silgnals mask
+const (
+	DTR = syscall.TIOCM_DTR
+	RTS = syscall.TIOCM_RTS
+	CTS = syscall.TIOCM_CTS
+	CAR = syscall.TIOCM_CAR
+	RNG = syscall.TIOCM_RNG
+	DSR = syscall.TIOCM_DSR
+	DCD = CAR // Data carrier detect, alias of CAR
+	RI  = RNG // Ring indicator, alias of RNG
+)
+
+// icounter mirrors Linux serial_icounter_struct, Darwin has no line counters.
+type icounter struct {
+	cts, dsr, rng, dcd          int32
+	rx, tx                      int32
+	frame, overrun, parity, brk int32
+	bufOverrun                  int32
+}
+
+// open opens path with flags, which carry the access mode (O_RDONLY, O_WRONLY or O_RDWR).
+func open(path string, flags int) (int, error) {
+	if flags&(syscall.O_CREAT|syscall.O_TRUNC|syscall.O_EXCL) != 0 {
+		return -1, errors.New("unsupported open flags")
+	}
+	fd, err := syscall.Open(path, flags|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0666)
+	if err == syscall.ENXIO || err == syscall.ENODEV {
+		return -1, ErrDeviceGone
+	}
+	if err != nil {
+		return -1, err
+	}
+	return fd, nil
+}
+
+func (s *Serial) ioctl(name string, req, arg uintptr) error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		req,
+		arg,
+	)
+	if e != 0 {
+		return os.NewSyscallError(name, e)
+	}
+	return nil
+}
+
+func (s *Serial) tcGetAttr(cfg *Termios) error {
+	return s.ioctl("tcgetattr", syscall.TIOCGETA, uintptr(unsafe.Pointer(cfg)))
+}
+
+// tcSetAttr sets attributes. Non standard speeds can't go through termios on Darwin,
+// they are set with IOSSIOSPEED after the rest of attributes.
+func (s *Serial) tcSetAttr(cfg *Termios) error {
+	if _, ok := baud[int(cfg.Ospeed)]; ok {
+		return s.ioctl("tcsetattr", syscall.TIOCSETA, uintptr(unsafe.Pointer(cfg)))
+	}
+	var cur Termios
+	if err := s.tcGetAttr(&cur); err != nil {
+		return err
+	}
+	t := *cfg
+	t.Ispeed, t.Ospeed = cur.Ispeed, cur.Ospeed
+	if _, ok := baud[int(t.Ospeed)]; !ok {
+		t.Ispeed, t.Ospeed = syscall.B9600, syscall.B9600
+	}
+	if err := s.ioctl("tcsetattr", syscall.TIOCSETA, uintptr(unsafe.Pointer(&t))); err != nil {
+		return err
+	}
+	speed := uint64(cfg.Ospeed)
+	return s.ioctl("iossiospeed", iossiospeed, uintptr(unsafe.Pointer(&speed)))
+}
+
+func (s *Serial) init() error {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	t.Iflag = (0)
+	t.Oflag = (0)
+	t.Lflag = (0)
+	t.Cflag = (bits[DefaultBits] | syscall.CLOCAL | syscall.HUPCL | syscall.CREAD)
+	t.Cc[syscall.VMIN] = 1
+	t.Cc[syscall.VTIME] = 0
+	t.Ispeed = baud[DefaultSpeed]
+	t.Ospeed = baud[DefaultSpeed]
+	if err := s.tcSetAttr(&t); err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateAttr applies fn to current serial attributes and commits them with a single tcSetAttr.
+// Nothing is committed if fn fails. Concurrent updates are serialized by s.cmu.
+func (s *Serial) updateAttr(fn func(t *Termios) error) error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	if err := fn(&t); err != nil {
+		return err
+	}
+	if err := s.tcSetAttr(&t); err != nil {
+		return err
+	}
+	s.attr = t.Clone()
+	return nil
+}
+
+func termBits(t *Termios, b int) error {
+	bb, ok := bits[b]
+	if !ok {
+		return errors.New("Usupported bits number")
+	}
+	t.Cflag &^= syscall.CSIZE
+	t.Cflag |= bb
+	return nil
+}
+
+// termSpeed sets speed b. Darwin stores the rate itself in Ispeed/Ospeed,
+// non standard rates are applied by tcSetAttr.
+func termSpeed(t *Termios, b int) error {
+	if err := checkSpeed(b); err != nil {
+		return err
+	}
+	t.Ispeed = uint64(b)
+	t.Ospeed = uint64(b)
+	return nil
+}
+
+// termGetSpeed returns speed set in t.
+func termGetSpeed(t *Termios) int {
+	return int(t.Ospeed)
+}
+
+func termParity(t *Termios, mode int) error {
+	switch mode {
+	case PAR_NONE:
+		t.Cflag &^= syscall.PARENB
+	case PAR_EVEN:
+		t.Cflag |= syscall.PARENB
+		t.Cflag &^= syscall.PARODD
+	case PAR_ODD:
+		t.Cflag |= syscall.PARENB
+		t.Cflag |= syscall.PARODD
+	case PAR_MARK, PAR_SPACE:
+		return ErrParityUnsupported
+	default:
+		return errors.New("invalid parity mode")
+	}
+	return nil
+}
+
+func termStopBits2(t *Termios, two bool) {
+	if two {
+		t.Cflag |= syscall.CSTOPB
+	} else {
+		t.Cflag &^= syscall.CSTOPB
+	}
+}
+
+// termStopBits sets stop bits 1, 2 or STOP_1_5. CSTOPB means 1.5 stop bits with 5 data bits,
+// so STOP_1_5 requires 5 data bits to be already set.
+func termStopBits(t *Termios, stop int) error {
+	switch stop {
+	case 1, 2:
+		termStopBits2(t, stop == 2)
+	case STOP_1_5:
+		if t.Cflag&syscall.CSIZE != syscall.CS5 {
+			return errors.New("1.5 stop bits require 5 data bits")
+		}
+		termStopBits2(t, true)
+	default:
+		return errors.New("Invalid stop bits number")
+	}
+	return nil
+}
+
+func termHwFlowCtrl(t *Termios, hw bool) {
+	if hw {
+		t.Cflag |= crtscts
+	} else {
+		t.Cflag &^= crtscts
+	}
+}
+
+func termSwFlowCtrl(t *Termios, sw bool) {
+	if sw {
+		t.Iflag |= (syscall.IXON | syscall.IXOFF | syscall.IXANY)
+	} else {
+		t.Iflag &^= (syscall.IXON | syscall.IXOFF | syscall.IXANY)
+	}
+}
+
+func (s *Serial) setBits(b int) error {
+	return s.updateAttr(func(t *Termios) error { return termBits(t, b) })
+}
+
+func (s *Serial) setSpeed(b int) error {
+	if err := s.updateAttr(func(t *Termios) error { return termSpeed(t, b) }); err != nil {
+		return err
+	}
+	if _, ok := baud[b]; ok {
+		return nil
+	}
+	// Drivers round arbitrary rates to what the hardware can do, check the result.
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	if got := termGetSpeed(&t); !speedMatch(b, got) {
+		return &SpeedMismatchError{Requested: b, Actual: got}
+	}
+	return nil
+}
+
+func (s *Serial) setParity(mode int) error {
+	return s.updateAttr(func(t *Termios) error { return termParity(t, mode) })
+}
+
+func (s *Serial) setStopBits(stop int) error {
+	return s.updateAttr(func(t *Termios) error { return termStopBits(t, stop) })
+}
+
+func (s *Serial) setHwFlowCtrl(hw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termHwFlowCtrl(t, hw)
+		return nil
+	})
+}
+
+func (s *Serial) setSwFlowCtrl(sw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termSwFlowCtrl(t, sw)
+		return nil
+	})
+}
+
+func (s *Serial) flowCtrl() (hw, sw bool, err error) {
+	var t Termios
+	if err = s.tcGetAttr(&t); err != nil {
+		return
+	}
+	return t.Cflag&crtscts != 0, t.Iflag&syscall.IXON != 0, nil
+}
+
+func termLocal(t *Termios, local bool) {
+	if local {
+		t.Cflag |= syscall.CLOCAL
+	} else {
+		t.Cflag &^= syscall.CLOCAL
+	}
+}
+
+func (s *Serial) setLocal(local bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termLocal(t, local)
+		return nil
+	})
+}
+
+func (s *Serial) setInputCheck(on bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		if on {
+			t.Iflag = (t.Iflag | syscall.INPCK | syscall.PARMRK) &^ (syscall.IGNPAR | syscall.ISTRIP)
+		} else {
+			t.Iflag &^= syscall.INPCK | syscall.PARMRK
+		}
+		return nil
+	})
+}
+
+func (s *Serial) setReadTimeout(vmin int, vtime time.Duration) error {
+	return s.updateAttr(func(t *Termios) error {
+		t.Cc[syscall.VMIN] = uint8(vmin)
+		t.Cc[syscall.VTIME] = uint8(vtime / (time.Second / 10))
+		return nil
+	})
+}
+
+func (s *Serial) setCanonical(erase, kill byte) error {
+	return s.updateAttr(func(t *Termios) error {
+		t.Lflag |= syscall.ICANON | syscall.ECHO | syscall.ECHOE | syscall.ECHOK
+		t.Iflag |= syscall.ICRNL
+		t.Oflag |= syscall.OPOST | syscall.ONLCR
+		t.Cc[syscall.VERASE] = erase
+		t.Cc[syscall.VKILL] = kill
+		return nil
+	})
+}
+
+func (s *Serial) setRaw(raw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		if raw {
+			t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+				syscall.INLCR | syscall.IGNCR | syscall.ICRNL
+			t.Oflag &^= syscall.OPOST
+			t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+			t.Cc[syscall.VMIN] = 1
+			t.Cc[syscall.VTIME] = 0
+		} else {
+			t.Iflag |= syscall.BRKINT | syscall.ICRNL
+			t.Oflag |= syscall.OPOST | syscall.ONLCR
+			t.Lflag |= syscall.ICANON | syscall.ECHO | syscall.ECHOE | syscall.ECHOK | syscall.ISIG | syscall.IEXTEN
+		}
+		return nil
+	})
+}
+
+func (s *Serial) setHup(hup bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		if hup {
+			t.Cflag |= syscall.HUPCL
+		} else {
+			t.Cflag &^= syscall.HUPCL
+		}
+		return nil
+	})
+}
+
+func (s *Serial) setExclusive(excl bool) error {
+	req := syscall.TIOCNXCL
+	if excl {
+		req = syscall.TIOCEXCL
+	}
+	return s.ioctl("exclusive", uintptr(req), 0)
+}
+
+func (s *Serial) setCtrlBit(ctr int, level bool) error {
+	var cmd uintptr
+	if level {
+		cmd = syscall.TIOCMBIS
+	} else {
+		cmd = syscall.TIOCMBIC
+	}
+	v := int32(ctr)
+	return s.ioctl("setCtrlBit", cmd, uintptr(unsafe.Pointer(&v)))
+}
+
+func (s *Serial) getCtrl() (int, error) {
+	var v int32
+	if err := s.ioctl("getCtrl", syscall.TIOCMGET, uintptr(unsafe.Pointer(&v))); err != nil {
+		return 0, err
+	}
+	return int(v), nil
+}
+
+func (s *Serial) setCtrl(ctr int) error {
+	v := int32(ctr)
+	return s.ioctl("setCtrl", syscall.TIOCMSET, uintptr(unsafe.Pointer(&v)))
+}
+
+func (s *Serial) inpWaiting() (int, error) {
+	var v int32
+	if err := s.ioctl("inpWaiting", fionread, uintptr(unsafe.Pointer(&v))); err != nil {
+		return 0, err
+	}
+	return int(v), nil
+}
+
+func (s *Serial) outWaiting() (int, error) {
+	var v int32
+	if err := s.ioctl("outWaiting", syscall.TIOCOUTQ, uintptr(unsafe.Pointer(&v))); err != nil {
+		return 0, err
+	}
+	return int(v), nil
+}
+
+func (s *Serial) flush(mode int) error {
+	var v int32
+	switch mode {
+	case FLUSH_I:
+		v = fread
+	case FLUSH_O:
+		v = fwrite
+	case FLUSH_IO:
+		v = fread | fwrite
+	default:
+		return errors.New("invalid flush mode")
+	}
+	return s.ioctl("flush", syscall.TIOCFLUSH, uintptr(unsafe.Pointer(&v)))
+}
+
+// getICount is unsupported, Darwin drivers don't count line events.
+func (s *Serial) getICount(c *icounter) error {
+	return ErrUnsupported
+}
+
+// waitCtrl is unsupported, Darwin lacks TIOCMIWAIT.
+func (s *Serial) waitCtrl(mask int) error {
+	return ErrUnsupported
+}
+
+func (s *Serial) ctrlCount(mask int) (n int, ok bool) {
+	return 0, false
+}
+
+func (s *Serial) tcDrain() error {
+	return s.ioctl("drain", syscall.TIOCDRAIN, 0)
+}
+
+// tcSendBreak sends a 0.4 seconds break, like tcsendbreak in Darwin libc.
+func (s *Serial) tcSendBreak() error {
+	if err := s.setBreak(true); err != nil {
+		return err
+	}
+	time.Sleep(400 * time.Millisecond)
+	return s.setBreak(false)
+}
+
+func (s *Serial) setBreak(on bool) error {
+	req := syscall.TIOCCBRK
+	if on {
+		req = syscall.TIOCSBRK
+	}
+	return s.ioctl("setbreak", uintptr(req), 0)
+}
+
+func termControlFlags(t *Termios) ControlFlagsInfo {
+	var cf ControlFlagsInfo
+	for k, v := range bits {
+		if v == t.Cflag&syscall.CSIZE {
+			cf.DataBits = k
+			break
+		}
+	}
+	cf.StopBits = 1
+	if t.Cflag&syscall.CSTOPB != 0 {
+		cf.StopBits = 2
+		if t.Cflag&syscall.CSIZE == syscall.CS5 {
+			cf.StopBits = STOP_1_5
+		}
+	}
+	switch {
+	case t.Cflag&syscall.PARENB == 0:
+		cf.Parity = PAR_NONE
+	case t.Cflag&syscall.PARODD != 0:
+		cf.Parity = PAR_ODD
+	default:
+		cf.Parity = PAR_EVEN
+	}
+	cf.HwFlow = t.Cflag&crtscts != 0
+	cf.Local = t.Cflag&syscall.CLOCAL != 0
+	cf.HangupOnClose = t.Cflag&syscall.HUPCL != 0
+	return cf
+}
+
+func (s *Serial) controlFlags() (ControlFlagsInfo, error) {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return ControlFlagsInfo{}, err
+	}
+	return termControlFlags(&t), nil
+}
+
+func (s *Serial) speed() (int, error) {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return 0, err
+	}
+	return termGetSpeed(&t), nil
+}
+
+// frameBits returns current speed and the number of bits per transmitted character.
+func (s *Serial) frameBits() (speed int, nbits int, err error) {
+	var t Termios
+	if err = s.tcGetAttr(&t); err != nil {
+		return
+	}
+	speed = termGetSpeed(&t)
+	cf := termControlFlags(&t)
+	stop := cf.StopBits
+	if stop == STOP_1_5 {
+		stop = 2 // Round up, timing based on it errs on the safe side
+	}
+	nbits = 1 + cf.DataBits + stop // Start, data and stop bits
+	if cf.Parity != PAR_NONE {
+		nbits++
+	}
+	return
+}
+
+// txEmpty reports whether the transmitter is empty, Darwin drivers can't tell (ok is false).
+func (s *Serial) txEmpty() (empty bool, ok bool) {
+	return false, false
+}
+
+// readv reads without blocking into bufs, it returns syscall.EAGAIN when no data is available.
+func (s *Serial) readv(bufs [][]byte) (int, error) {
+	iov := make([]syscall.Iovec, 0, len(bufs))
+	for _, b := range bufs {
+		if len(b) > 0 {
+			v := syscall.Iovec{Base: &b[0]}
+			v.SetLen(len(b))
+			iov = append(iov, v)
+		}
+	}
+	if len(iov) == 0 {
+		return 0, nil
+	}
+	n, _, e := syscall.Syscall(
+		syscall.SYS_READV,
+		uintptr(s.file().Fd()),
+		uintptr(unsafe.Pointer(&iov[0])),
+		uintptr(len(iov)),
+	)
+	if e == syscall.EAGAIN {
+		return 0, syscall.EAGAIN
+	}
+	if e != 0 {
+		return 0, os.NewSyscallError("readv", e)
+	}
+	return int(n), nil
+}
+
+// waitIn waits up to d for input (or hangup) on serial file and reports whether it's ready.
+func (s *Serial) waitIn(d time.Duration) (bool, error) {
+	pfd := pollFd{fd: int32(s.file().Fd()), events: pollIn}
+	ms := (d + time.Millisecond - 1) / time.Millisecond
+	n, _, e := syscall.Syscall(
+		syscall.SYS_POLL,
+		uintptr(unsafe.Pointer(&pfd)),
+		1,
+		uintptr(ms),
+	)
+	if e == syscall.EINTR {
+		return false, nil
+	}
+	if e != 0 {
+		return false, os.NewSyscallError("poll", e)
+	}
+	return n > 0, nil
+}
+
+func (s *Serial) makeControllingTerminal() error {
+	if _, err := syscall.Setsid(); err != nil {
+		return os.NewSyscallError("setsid", err)
+	}
+	return s.ioctl("MakeControllingTerminal", syscall.TIOCSCTTY, 0)
+}
+
+// setRS485 is unsupported, Darwin has no RS-485 ioctls.
+func (s *Serial) setRS485(cfg RS485Config) error {
+	return ErrUnsupported
+}
+
+func (s *Serial) getRS485() (RS485Config, error) {
+	return RS485Config{}, ErrUnsupported
+}
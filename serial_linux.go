@@ -53,10 +53,29 @@ var bits = map[int]uint32{
 
 // Constants not defined in syscall module
 const (
-	cbaud   = 0010017
-	cbaudex = 0010000
-	crtscts = 020000000000
-	tcflsh  = 0x540B
+	cbaud         = 0010017
+	cbaudex       = 0010000
+	crtscts       = 020000000000
+	tcflsh        = 0x540B
+	tiocgicnt     = 0x545D
+	tcsbrk        = 0x5409
+	tiocsergetlsr = 0x5459
+	tiocserTemt   = 0x01
+	bother        = 0010000
+	tcgets2       = 0x802C542A
+	tcsets2       = 0x402C542B
+	tiocgrs485    = 0x542E
+	tiocsrs485    = 0x542F
+	tiocmiwait    = 0x545C
+	cmspar        = 010000000000
+)
+
+// serial_rs485 flags
+const (
+	rs485Enabled      = 1 << 0
+	rs485RtsOnSend    = 1 << 1
+	rs485RtsAfterSend = 1 << 2
+	rs485RxDuringTx   = 1 << 4
 )
 
 // Constants for modem control silgnals mask
@@ -67,10 +86,44 @@ const (
 	CAR = syscall.TIOCM_CAR
 	RNG = syscall.TIOCM_RNG
 	DSR = syscall.TIOCM_DSR
+	DCD = CAR // Data carrier detect, alias of CAR
+	RI  = RNG // Ring indicator, alias of RNG
 )
 
-func open(path string) (int, error) {
-	fd, err := syscall.Open(path, syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0666)
+// termios2 mirrors kernel's struct termios2, used for arbitrary baud rates (BOTHER).
+type termios2 struct {
+	iflag, oflag, cflag, lflag uint32
+	line                       uint8
+	cc                         [19]uint8
+	ispeed, ospeed             uint32
+}
+
+// rs485 mirrors kernel's struct serial_rs485.
+type rs485 struct {
+	flags       uint32
+	delayBefore uint32 // Milliseconds
+	delayAfter  uint32 // Milliseconds
+	padding     [5]uint32
+}
+
+// icounter mirrors kernel's struct serial_icounter_struct.
+type icounter struct {
+	cts, dsr, rng, dcd          int32
+	rx, tx                      int32
+	frame, overrun, parity, brk int32
+	bufOverrun                  int32
+	reserved                    [9]int32
+}
+
+// open opens path with flags, which carry the access mode (O_RDONLY, O_WRONLY or O_RDWR).
+func open(path string, flags int) (int, error) {
+	if flags&(syscall.O_CREAT|syscall.O_TRUNC|syscall.O_EXCL) != 0 {
+		return -1, errors.New("unsupported open flags")
+	}
+	fd, err := syscall.Open(path, flags|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0666)
+	if err == syscall.ENXIO || err == syscall.ENODEV {
+		return -1, ErrDeviceGone
+	}
 	if err != nil {
 		return -1, err
 	}
@@ -80,20 +133,40 @@ func open(path string) (int, error) {
 func (s *Serial) tcGetAttr(cfg *Termios) error {
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		syscall.TCGETS,
 		uintptr(unsafe.Pointer(cfg)),
 	)
 	if e != 0 {
 		return os.NewSyscallError("tcgetattr", e)
 	}
+	if cfg.Cflag&cbaud == bother {
+		var t2 termios2
+		if err := s.ioctlTermios2(tcgets2, &t2); err != nil {
+			return err
+		}
+		cfg.Ispeed, cfg.Ospeed = t2.ispeed, t2.ospeed
+	}
 	return nil
 }
 
 func (s *Serial) tcSetAttr(cfg *Termios) error {
+	if cfg.Cflag&cbaud == bother {
+		t2 := termios2{
+			iflag:  cfg.Iflag,
+			oflag:  cfg.Oflag,
+			cflag:  cfg.Cflag,
+			lflag:  cfg.Lflag,
+			line:   cfg.Line,
+			ispeed: cfg.Ispeed,
+			ospeed: cfg.Ospeed,
+		}
+		copy(t2.cc[:], cfg.Cc[:])
+		return s.ioctlTermios2(tcsets2, &t2)
+	}
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		syscall.TCSETS,
 		uintptr(unsafe.Pointer(cfg)),
 	)
@@ -103,6 +176,19 @@ func (s *Serial) tcSetAttr(cfg *Termios) error {
 	return nil
 }
 
+func (s *Serial) ioctlTermios2(req uintptr, t2 *termios2) error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		req,
+		uintptr(unsafe.Pointer(t2)),
+	)
+	if e != 0 {
+		return os.NewSyscallError("termios2", e)
+	}
+	return nil
+}
+
 func (s *Serial) init() error {
 	var t Termios
 	if err := s.tcGetAttr(&t); err != nil {
@@ -111,166 +197,294 @@ func (s *Serial) init() error {
 	t.Iflag = (0)
 	t.Oflag = (0)
 	t.Lflag = (0)
-	t.Cflag = (baud[9600] | bits[8] | syscall.CLOCAL | syscall.HUPCL | syscall.CREAD)
+	t.Cflag = (baud[DefaultSpeed] | bits[DefaultBits] | syscall.CLOCAL | syscall.HUPCL | syscall.CREAD)
 	t.Cc[syscall.VMIN] = 1
 	t.Cc[syscall.VTIME] = 0
-	t.Ispeed = baud[9600]
-	t.Ospeed = baud[9600]
+	t.Ispeed = baud[DefaultSpeed]
+	t.Ospeed = baud[DefaultSpeed]
 	if err := s.tcSetAttr(&t); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *Serial) setBits(b int) error {
+// updateAttr applies fn to current serial attributes and commits them with a single tcSetAttr.
+// Nothing is committed if fn fails. Concurrent updates are serialized by s.cmu.
+func (s *Serial) updateAttr(fn func(t *Termios) error) error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
 	var t Termios
-	bb, ok := bits[b]
-	if !ok {
-		return errors.New("Usupported bits number")
-	}
 	if err := s.tcGetAttr(&t); err != nil {
 		return err
 	}
-	t.Cflag &^= (syscall.CS5 | syscall.CS6 | syscall.CS7 | syscall.CS8)
-	t.Cflag |= bb
+	orig := t
+	if err := fn(&t); err != nil {
+		return err
+	}
 	if err := s.tcSetAttr(&t); err != nil {
 		return err
 	}
+	if t.Cflag&(syscall.PARENB|cmspar) == syscall.PARENB|cmspar {
+		// Drivers without mark/space support clear CMSPAR, falling back to even/odd.
+		var got Termios
+		if err := s.tcGetAttr(&got); err != nil {
+			return err
+		}
+		if got.Cflag&cmspar == 0 {
+			s.tcSetAttr(&orig)
+			return ErrParityUnsupported
+		}
+	}
+	s.attr = t.Clone()
 	return nil
 }
 
-func (s *Serial) setSpeed(b int) error {
-	var t Termios
-	bb, ok := baud[b]
+func termBits(t *Termios, b int) error {
+	bb, ok := bits[b]
 	if !ok {
-		return errors.New("Unknown baud rate")
+		return errors.New("Usupported bits number")
 	}
-	if err := s.tcGetAttr(&t); err != nil {
+	t.Cflag &^= (syscall.CS5 | syscall.CS6 | syscall.CS7 | syscall.CS8)
+	t.Cflag |= bb
+	return nil
+}
+
+// termSpeed sets speed b. Non standard rates use BOTHER with the exact rate in Ispeed/Ospeed.
+func termSpeed(t *Termios, b int) error {
+	if err := checkSpeed(b); err != nil {
 		return err
 	}
+	bb, ok := baud[b]
+	if !ok {
+		bb = bother
+		t.Cflag &^= cbaud | cbaudex
+		t.Cflag |= bb
+		t.Ispeed = uint32(b)
+		t.Ospeed = uint32(b)
+		return nil
+	}
 	t.Cflag &^= cbaud | cbaudex
 	t.Cflag |= bb
 	t.Ispeed = bb
 	t.Ospeed = bb
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
-	}
 	return nil
 }
 
-func (s *Serial) setParity(mode int) error {
-	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
+// termGetSpeed returns speed set in t, 0 if unknown.
+func termGetSpeed(t *Termios) int {
+	if t.Cflag&cbaud == bother {
+		return int(t.Ospeed)
+	}
+	for k, v := range baud {
+		if v == t.Cflag&cbaud {
+			return k
+		}
 	}
+	return 0
+}
+
+func termParity(t *Termios, mode int) error {
 	switch mode {
 	case PAR_NONE:
-		t.Cflag &^= syscall.PARENB
+		t.Cflag &^= syscall.PARENB | cmspar
 	case PAR_EVEN:
 		t.Cflag |= syscall.PARENB
-		t.Cflag &^= syscall.PARODD
+		t.Cflag &^= syscall.PARODD | cmspar
 	case PAR_ODD:
 		t.Cflag |= syscall.PARENB
 		t.Cflag |= syscall.PARODD
+		t.Cflag &^= cmspar
+	case PAR_MARK:
+		t.Cflag |= syscall.PARENB | cmspar | syscall.PARODD
+	case PAR_SPACE:
+		t.Cflag |= syscall.PARENB | cmspar
+		t.Cflag &^= syscall.PARODD
 	default:
 		return errors.New("invalid parity mode")
 	}
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
-	}
 	return nil
 }
 
-func (s *Serial) setStopBits2(two bool) error {
-	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
-	}
+func termStopBits2(t *Termios, two bool) {
 	if two {
 		t.Cflag |= syscall.CSTOPB
 	} else {
 		t.Cflag &^= syscall.CSTOPB
 	}
+}
 
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
+// termStopBits sets stop bits 1, 2 or STOP_1_5. CSTOPB means 1.5 stop bits with 5 data bits,
+// so STOP_1_5 requires 5 data bits to be already set.
+func termStopBits(t *Termios, stop int) error {
+	switch stop {
+	case 1, 2:
+		termStopBits2(t, stop == 2)
+	case STOP_1_5:
+		if t.Cflag&syscall.CSIZE != syscall.CS5 {
+			return errors.New("1.5 stop bits require 5 data bits")
+		}
+		termStopBits2(t, true)
+	default:
+		return errors.New("Invalid stop bits number")
 	}
 	return nil
 }
 
-func (s *Serial) setHwFlowCtrl(hw bool) error {
-	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
-	}
+func termHwFlowCtrl(t *Termios, hw bool) {
 	if hw {
 		t.Cflag |= crtscts
 	} else {
 		t.Cflag &^= crtscts
 	}
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
-	}
-	return nil
 }
 
-func (s *Serial) setSwFlowCtrl(sw bool) error {
-	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
-	}
+func termSwFlowCtrl(t *Termios, sw bool) {
 	if sw {
 		t.Iflag |= (syscall.IXON | syscall.IXOFF | syscall.IXANY)
 	} else {
 		t.Iflag &^= (syscall.IXON | syscall.IXOFF | syscall.IXANY)
 	}
-	if err := s.tcSetAttr(&t); err != nil {
+}
+
+func (s *Serial) setBits(b int) error {
+	return s.updateAttr(func(t *Termios) error { return termBits(t, b) })
+}
+
+func (s *Serial) setSpeed(b int) error {
+	if err := s.updateAttr(func(t *Termios) error { return termSpeed(t, b) }); err != nil {
 		return err
 	}
+	if _, ok := baud[b]; ok {
+		return nil
+	}
+	// Drivers round arbitrary rates to what the hardware can do, check the result.
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	if got := termGetSpeed(&t); !speedMatch(b, got) {
+		return &SpeedMismatchError{Requested: b, Actual: got}
+	}
 	return nil
 }
 
-func (s *Serial) setLocal(local bool) error {
+func (s *Serial) setParity(mode int) error {
+	return s.updateAttr(func(t *Termios) error { return termParity(t, mode) })
+}
+
+func (s *Serial) setStopBits(stop int) error {
+	return s.updateAttr(func(t *Termios) error { return termStopBits(t, stop) })
+}
+
+func (s *Serial) setHwFlowCtrl(hw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termHwFlowCtrl(t, hw)
+		return nil
+	})
+}
+
+func (s *Serial) setSwFlowCtrl(sw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termSwFlowCtrl(t, sw)
+		return nil
+	})
+}
+
+func (s *Serial) flowCtrl() (hw, sw bool, err error) {
 	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
+	if err = s.tcGetAttr(&t); err != nil {
+		return
 	}
+	return t.Cflag&crtscts != 0, t.Iflag&syscall.IXON != 0, nil
+}
+
+func termLocal(t *Termios, local bool) {
 	if local {
 		t.Cflag |= syscall.CLOCAL
 	} else {
 		t.Cflag &^= syscall.CLOCAL
 	}
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
-	}
-	return nil
+}
+
+func (s *Serial) setLocal(local bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termLocal(t, local)
+		return nil
+	})
+}
+
+func (s *Serial) setInputCheck(on bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		if on {
+			t.Iflag = (t.Iflag | syscall.INPCK | syscall.PARMRK) &^ (syscall.IGNPAR | syscall.ISTRIP)
+		} else {
+			t.Iflag &^= syscall.INPCK | syscall.PARMRK
+		}
+		return nil
+	})
 }
 
 func (s *Serial) setReadTimeout(vmin int, vtime time.Duration) error {
-	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
-	}
-	t.Cc[syscall.VMIN] = uint8(vmin)
-	t.Cc[syscall.VTIME] = uint8(vtime / (time.Second / 10))
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
-	}
-	return nil
+	return s.updateAttr(func(t *Termios) error {
+		t.Cc[syscall.VMIN] = uint8(vmin)
+		t.Cc[syscall.VTIME] = uint8(vtime / (time.Second / 10))
+		return nil
+	})
+}
+
+func (s *Serial) setCanonical(erase, kill byte) error {
+	return s.updateAttr(func(t *Termios) error {
+		t.Lflag |= syscall.ICANON | syscall.ECHO | syscall.ECHOE | syscall.ECHOK
+		t.Iflag |= syscall.ICRNL
+		t.Oflag |= syscall.OPOST | syscall.ONLCR
+		t.Cc[syscall.VERASE] = erase
+		t.Cc[syscall.VKILL] = kill
+		return nil
+	})
+}
+
+func (s *Serial) setRaw(raw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		if raw {
+			t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+				syscall.INLCR | syscall.IGNCR | syscall.ICRNL
+			t.Oflag &^= syscall.OPOST
+			t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+			t.Cc[syscall.VMIN] = 1
+			t.Cc[syscall.VTIME] = 0
+		} else {
+			t.Iflag |= syscall.BRKINT | syscall.ICRNL
+			t.Oflag |= syscall.OPOST | syscall.ONLCR
+			t.Lflag |= syscall.ICANON | syscall.ECHO | syscall.ECHOE | syscall.ECHOK | syscall.ISIG | syscall.IEXTEN
+		}
+		return nil
+	})
 }
 
 func (s *Serial) setHup(hup bool) error {
-	var t Termios
-	if err := s.tcGetAttr(&t); err != nil {
-		return err
-	}
-	if hup {
-		t.Cflag |= syscall.HUPCL
-	} else {
-		t.Cflag &^= syscall.HUPCL
+	return s.updateAttr(func(t *Termios) error {
+		if hup {
+			t.Cflag |= syscall.HUPCL
+		} else {
+			t.Cflag &^= syscall.HUPCL
+		}
+		return nil
+	})
+}
+
+func (s *Serial) setExclusive(excl bool) error {
+	req := syscall.TIOCNXCL
+	if excl {
+		req = syscall.TIOCEXCL
 	}
-	if err := s.tcSetAttr(&t); err != nil {
-		return err
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		uintptr(req),
+		0,
+	)
+	if e != 0 {
+		return os.NewSyscallError("exclusive", e)
 	}
 	return nil
 }
@@ -284,7 +498,7 @@ func (s *Serial) setCtrlBit(ctr int, level bool) error {
 	}
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		cmd,
 		uintptr(unsafe.Pointer(&ctr)),
 	)
@@ -298,7 +512,7 @@ func (s *Serial) getCtrl() (int, error) {
 	v := 0
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		uintptr(syscall.TIOCMGET),
 		uintptr(unsafe.Pointer(&v)),
 	)
@@ -311,7 +525,7 @@ func (s *Serial) getCtrl() (int, error) {
 func (s *Serial) setCtrl(ctr int) error {
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		uintptr(syscall.TIOCMSET),
 		uintptr(unsafe.Pointer(&ctr)),
 	)
@@ -326,7 +540,7 @@ func (s *Serial) inpWaiting() (int, error) {
 	cmd := uintptr(syscall.TIOCINQ)
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		cmd,
 		uintptr(unsafe.Pointer(&v)),
 	)
@@ -341,7 +555,7 @@ func (s *Serial) outWaiting() (int, error) {
 	cmd := uintptr(syscall.TIOCOUTQ)
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		cmd,
 		uintptr(unsafe.Pointer(&v)),
 	)
@@ -366,7 +580,7 @@ func (s *Serial) flush(mode int) error {
 	}
 	_, _, e := syscall.Syscall(
 		syscall.SYS_IOCTL,
-		uintptr(s.f.Fd()),
+		uintptr(s.file().Fd()),
 		cmd,
 		uintptr(v),
 	)
@@ -375,3 +589,299 @@ func (s *Serial) flush(mode int) error {
 	}
 	return nil
 }
+
+func (s *Serial) getICount(c *icounter) error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		tiocgicnt,
+		uintptr(unsafe.Pointer(c)),
+	)
+	if e != 0 {
+		return os.NewSyscallError("getICount", e)
+	}
+	return nil
+}
+
+// waitCtrl blocks in the kernel until a modem status line in mask changes.
+func (s *Serial) waitCtrl(mask int) error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		tiocmiwait,
+		uintptr(mask),
+	)
+	if e == syscall.ENOTTY || e == syscall.EINVAL {
+		return ErrUnsupported
+	}
+	if e != 0 {
+		return os.NewSyscallError("waitCtrl", e)
+	}
+	return nil
+}
+
+// ctrlCount returns the number of transitions seen on modem status lines in mask,
+// ok is false if the driver doesn't count them.
+func (s *Serial) ctrlCount(mask int) (n int, ok bool) {
+	var c icounter
+	if s.getICount(&c) != nil {
+		return 0, false
+	}
+	for bit, cnt := range map[int]int32{CTS: c.cts, DSR: c.dsr, RNG: c.rng, CAR: c.dcd} {
+		if mask&bit != 0 {
+			n += int(cnt)
+		}
+	}
+	return n, true
+}
+
+func (s *Serial) tcDrain() error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		tcsbrk,
+		1, // Non zero argument means tcdrain
+	)
+	if e != 0 {
+		return os.NewSyscallError("drain", e)
+	}
+	return nil
+}
+
+func (s *Serial) tcSendBreak() error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		tcsbrk,
+		0, // Zero argument means tcsendbreak (0.25 to 0.5 seconds)
+	)
+	if e != 0 {
+		return os.NewSyscallError("sendbreak", e)
+	}
+	return nil
+}
+
+func (s *Serial) setBreak(on bool) error {
+	req := syscall.TIOCCBRK
+	if on {
+		req = syscall.TIOCSBRK
+	}
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		uintptr(req),
+		0,
+	)
+	if e != 0 {
+		return os.NewSyscallError("setbreak", e)
+	}
+	return nil
+}
+
+func termControlFlags(t *Termios) ControlFlagsInfo {
+	var cf ControlFlagsInfo
+	for k, v := range bits {
+		if v == t.Cflag&syscall.CSIZE {
+			cf.DataBits = k
+			break
+		}
+	}
+	cf.StopBits = 1
+	if t.Cflag&syscall.CSTOPB != 0 {
+		cf.StopBits = 2
+		if t.Cflag&syscall.CSIZE == syscall.CS5 {
+			cf.StopBits = STOP_1_5
+		}
+	}
+	switch {
+	case t.Cflag&syscall.PARENB == 0:
+		cf.Parity = PAR_NONE
+	case t.Cflag&cmspar != 0 && t.Cflag&syscall.PARODD != 0:
+		cf.Parity = PAR_MARK
+	case t.Cflag&cmspar != 0:
+		cf.Parity = PAR_SPACE
+	case t.Cflag&syscall.PARODD != 0:
+		cf.Parity = PAR_ODD
+	default:
+		cf.Parity = PAR_EVEN
+	}
+	cf.HwFlow = t.Cflag&crtscts != 0
+	cf.Local = t.Cflag&syscall.CLOCAL != 0
+	cf.HangupOnClose = t.Cflag&syscall.HUPCL != 0
+	return cf
+}
+
+func (s *Serial) controlFlags() (ControlFlagsInfo, error) {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return ControlFlagsInfo{}, err
+	}
+	return termControlFlags(&t), nil
+}
+
+func (s *Serial) speed() (int, error) {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return 0, err
+	}
+	return termGetSpeed(&t), nil
+}
+
+// frameBits returns current speed and the number of bits per transmitted character.
+func (s *Serial) frameBits() (speed int, nbits int, err error) {
+	var t Termios
+	if err = s.tcGetAttr(&t); err != nil {
+		return
+	}
+	speed = termGetSpeed(&t)
+	cf := termControlFlags(&t)
+	stop := cf.StopBits
+	if stop == STOP_1_5 {
+		stop = 2 // Round up, timing based on it errs on the safe side
+	}
+	nbits = 1 + cf.DataBits + stop // Start, data and stop bits
+	if cf.Parity != PAR_NONE {
+		nbits++
+	}
+	return
+}
+
+// txEmpty reports whether the transmitter is empty, ok is false if the driver can't tell.
+func (s *Serial) txEmpty() (empty bool, ok bool) {
+	var lsr uint32
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		tiocsergetlsr,
+		uintptr(unsafe.Pointer(&lsr)),
+	)
+	if e != 0 {
+		return false, false
+	}
+	return lsr&tiocserTemt != 0, true
+}
+
+// readv reads without blocking into bufs, it returns syscall.EAGAIN when no data is available.
+func (s *Serial) readv(bufs [][]byte) (int, error) {
+	iov := make([]syscall.Iovec, 0, len(bufs))
+	for _, b := range bufs {
+		if len(b) > 0 {
+			v := syscall.Iovec{Base: &b[0]}
+			v.SetLen(len(b))
+			iov = append(iov, v)
+		}
+	}
+	if len(iov) == 0 {
+		return 0, nil
+	}
+	n, _, e := syscall.Syscall(
+		syscall.SYS_READV,
+		uintptr(s.file().Fd()),
+		uintptr(unsafe.Pointer(&iov[0])),
+		uintptr(len(iov)),
+	)
+	if e == syscall.EAGAIN {
+		return 0, syscall.EAGAIN
+	}
+	if e != 0 {
+		return 0, os.NewSyscallError("readv", e)
+	}
+	return int(n), nil
+}
+
+// waitIn waits up to d for input (or hangup) on serial file and reports whether it's ready.
+func (s *Serial) waitIn(d time.Duration) (bool, error) {
+	pfd := pollFd{fd: int32(s.file().Fd()), events: pollIn}
+	ts := syscall.NsecToTimespec(int64(d))
+	n, _, e := syscall.Syscall6(
+		syscall.SYS_PPOLL,
+		uintptr(unsafe.Pointer(&pfd)),
+		1,
+		uintptr(unsafe.Pointer(&ts)),
+		0, 0, 0,
+	)
+	if e == syscall.EINTR {
+		return false, nil
+	}
+	if e != 0 {
+		return false, os.NewSyscallError("ppoll", e)
+	}
+	return n > 0, nil
+}
+
+func (s *Serial) makeControllingTerminal() error {
+	if _, err := syscall.Setsid(); err != nil {
+		return os.NewSyscallError("setsid", err)
+	}
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		syscall.TIOCSCTTY,
+		0,
+	)
+	if e != 0 {
+		return os.NewSyscallError("MakeControllingTerminal", e)
+	}
+	return nil
+}
+
+func (s *Serial) ioctlRS485(req uintptr, r *rs485) error {
+	_, _, e := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		uintptr(s.file().Fd()),
+		req,
+		uintptr(unsafe.Pointer(r)),
+	)
+	if e == syscall.ENOTTY || e == syscall.EINVAL {
+		return ErrUnsupported
+	}
+	if e != 0 {
+		return os.NewSyscallError("rs485", e)
+	}
+	return nil
+}
+
+func (s *Serial) setRS485(cfg RS485Config) error {
+	r := rs485{
+		delayBefore: uint32((cfg.DelayBeforeSend + time.Millisecond - 1) / time.Millisecond),
+		delayAfter:  uint32((cfg.DelayAfterSend + time.Millisecond - 1) / time.Millisecond),
+	}
+	for flag, on := range map[uint32]bool{
+		rs485Enabled:      cfg.Enabled,
+		rs485RtsOnSend:    cfg.RtsOnSend,
+		rs485RtsAfterSend: cfg.RtsAfterSend,
+		rs485RxDuringTx:   cfg.RxDuringTx,
+	} {
+		if on {
+			r.flags |= flag
+		}
+	}
+	if err := s.ioctlRS485(tiocsrs485, &r); err != nil {
+		return err
+	}
+	// Some drivers accept the ioctl but silently ignore RS-485 mode.
+	if cfg.Enabled {
+		if err := s.ioctlRS485(tiocgrs485, &r); err != nil {
+			return err
+		}
+		if r.flags&rs485Enabled == 0 {
+			return ErrUnsupported
+		}
+	}
+	return nil
+}
+
+func (s *Serial) getRS485() (RS485Config, error) {
+	var r rs485
+	if err := s.ioctlRS485(tiocgrs485, &r); err != nil {
+		return RS485Config{}, err
+	}
+	return RS485Config{
+		Enabled:         r.flags&rs485Enabled != 0,
+		RtsOnSend:       r.flags&rs485RtsOnSend != 0,
+		RtsAfterSend:    r.flags&rs485RtsAfterSend != 0,
+		RxDuringTx:      r.flags&rs485RxDuringTx != 0,
+		DelayBeforeSend: time.Duration(r.delayBefore) * time.Millisecond,
+		DelayAfterSend:  time.Duration(r.delayAfter) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package serial
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// openTestPair opens a pseudo terminal pair, skipping the test where there are none.
+func openTestPair(t *testing.T) (a, b *Serial) {
+	t.Helper()
+	a, b, err := OpenPair()
+	if err != nil {
+		t.Skipf("no pseudo terminals: %v", err)
+	}
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return a, b
+}
+
+func TestReconfigureDropsReadAhead(t *testing.T) {
+	steps := []struct {
+		name string
+		fn   func(s *Serial) error
+	}{
+		{"SetSpeed", func(s *Serial) error { return s.SetSpeed(19200) }},
+		{"SetParity", func(s *Serial) error { return s.SetParity(PAR_EVEN) }},
+		{"SetBits", func(s *Serial) error { return s.SetBits(7) }},
+		{"Flush", func(s *Serial) error { return s.Flush(FLUSH_I) }},
+	}
+	for _, st := range steps {
+		t.Run(st.name, func(t *testing.T) {
+			a, b := openTestPair(t)
+			b.SetReadTimeout(100 * time.Millisecond)
+			if _, err := a.WriteString("stale"); err != nil {
+				t.Fatal(err)
+			}
+			if c, err := b.ReadByte(); err != nil || c != 's' {
+				t.Fatalf("ReadByte = %q, %v", c, err)
+			}
+			if n := b.buffered(); n == 0 {
+				t.Fatal("no bytes read ahead")
+			}
+			if err := st.fn(b); err != nil {
+				t.Fatal(err)
+			}
+			if n := b.buffered(); n != 0 {
+				t.Fatalf("%d bytes still read ahead", n)
+			}
+			if _, err := a.WriteString("new"); err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]byte, 16)
+			n, err := b.Read(buf)
+			if err != nil || string(buf[:n]) != "new" {
+				t.Fatalf("Read = %q, %v; want \"new\"", buf[:n], err)
+			}
+		})
+	}
+}
+
+func TestSetterErrors(t *testing.T) {
+	a, _ := openTestPair(t)
+	a.SetStrict(true)
+	tests := []struct {
+		op string
+		fn func() error
+	}{
+		{"ApplyConfig", func() error { return a.ApplyConfig(Config{HwFlowCtrl: true, SwFlowCtrl: true}) }},
+		{"SetMode", func() error { return a.SetMode("9600,8,N") }},
+		{"SetFlowControlMode", func() error { return a.SetFlowControlMode(FLOW_BOTH, false) }},
+		{"SetReadIntervalTimeout", func() error { return a.SetReadIntervalTimeout(256, 0) }},
+	}
+	for _, tt := range tests {
+		var se *SerialError
+		if err := tt.fn(); !errors.As(err, &se) || se.Op != tt.op || se.Path != a.Name() {
+			t.Errorf("%s error = %v, want SerialError for %s", tt.op, err, a.Name())
+		}
+	}
+}
+
+func TestLineReaderExpiredDeadline(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadDeadline(time.Now().Add(-time.Second))
+	lines, errs, stop := b.StartLineReader()
+	defer stop()
+	a.WriteString("hello\r\n")
+	select {
+	case line := <-lines:
+		if line != "hello" {
+			t.Fatalf("line = %q, want \"hello\"", line)
+		}
+	case err := <-errs:
+		t.Fatalf("line reader error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("no line received")
+	}
+}
+
+func TestReadAvailableOnlyEcho(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SuppressEcho = true
+	b.WriteString("abc")
+	got := make([]byte, 3)
+	if _, err := io.ReadFull(a, got); err != nil {
+		t.Fatal(err)
+	}
+	a.Write(got) // Loop the bytes back as a half duplex line would
+	if err := b.WaitForBytes(3, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 8)
+		if n, err := b.ReadAvailable(buf); n != 0 || err != nil {
+			t.Errorf("ReadAvailable = %d, %v, want 0, nil", n, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		b.Close()
+		t.Fatal("ReadAvailable blocked on echo")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	if _, err := b.Peek(-1); err == nil {
+		t.Fatal("Peek(-1) succeeded")
+	}
+	a.WriteString("abcdef")
+	if got, err := b.Peek(3); err != nil || string(got) != "abc" {
+		t.Fatalf("Peek(3) = %q, %v, want \"abc\"", got, err)
+	}
+	got := make([]byte, 6)
+	if _, err := io.ReadFull(b, got); err != nil || string(got) != "abcdef" {
+		t.Fatalf("read after Peek = %q, %v, want \"abcdef\"", got, err)
+	}
+}
+
+func TestEndReadOutOfOrder(t *testing.T) {
+	a, b := openTestPair(t)
+	bg := b.beginBackgroundRead()
+	op := b.beginRead(time.Minute)
+	b.endRead(bg) // Background reader ends while the nested op is on top
+	b.endRead(op)
+	b.dmu.Lock()
+	rop := b.rop
+	b.dmu.Unlock()
+	if rop != nil {
+		t.Fatal("read operation left linked")
+	}
+	// The explicit deadline applies again.
+	a.WriteString("x")
+	b.SetReadDeadline(time.Now().Add(-time.Second))
+	if _, err := b.Read(make([]byte, 1)); err != ErrTimeout {
+		t.Fatalf("Read past deadline error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestSendFlowCharWritePath(t *testing.T) {
+	a, b := openTestPair(t)
+	a.SetReadTimeout(time.Second)
+	b.SetReadTimeout(time.Second)
+	var tab [256]byte
+	for i := range tab {
+		tab[i] = byte(i)
+	}
+	tab[XOFF] = 'x'
+	b.SetWriteTranslation(&tab)
+	b.SuppressEcho = true
+	if err := b.SendFlowChar(false); err != nil {
+		t.Fatal(err)
+	}
+	if st := b.Stats(); st.BytesWritten != 1 {
+		t.Fatalf("BytesWritten = %d, want 1", st.BytesWritten)
+	}
+	c, err := a.ReadByte()
+	if err != nil || c != XOFF {
+		t.Fatalf("peer got %#x, %v, want XOFF untranslated", c, err)
+	}
+	// The looped back XOFF is taken as our own echo, not a mismatch.
+	a.Write([]byte{XOFF, 'k'})
+	if c, err := b.ReadByte(); err != nil || c != 'k' {
+		t.Fatalf("ReadByte = %q, %v, want 'k'", c, err)
+	}
+}
@@ -0,0 +1,97 @@
+//go:build !windows
+
+package serial
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/jaracil/poll"
+)
+
+// file is the serial file type, a pollable descriptor on unix systems.
+type file = poll.File
+
+var ErrTimeout = poll.ErrTimeout
+var ErrClosed = poll.ErrClosed
+
+// Control character indices for Termios.CC and Termios.SetCC.
+const (
+	VINTR    = syscall.VINTR    // Interrupt (Ex. 0x03)
+	VQUIT    = syscall.VQUIT    // Quit
+	VERASE   = syscall.VERASE   // Erase character in canonical mode
+	VKILL    = syscall.VKILL    // Erase line in canonical mode
+	VEOF     = syscall.VEOF     // End of file in canonical mode
+	VEOL     = syscall.VEOL     // Additional end of line
+	VEOL2    = syscall.VEOL2    // Another additional end of line
+	VSTART   = syscall.VSTART   // XON, resumes output with software flow control (default 0x11)
+	VSTOP    = syscall.VSTOP    // XOFF, stops output with software flow control (default 0x13)
+	VSUSP    = syscall.VSUSP    // Suspend
+	VREPRINT = syscall.VREPRINT // Reprint line in canonical mode
+	VWERASE  = syscall.VWERASE  // Erase word in canonical mode
+	VLNEXT   = syscall.VLNEXT   // Literal next
+	VDISCARD = syscall.VDISCARD // Toggle output discarding
+	VMIN     = syscall.VMIN     // Minimum bytes for a non canonical read (see SetReadIntervalTimeout)
+	VTIME    = syscall.VTIME    // Non canonical read timeout in tenths of second (see SetReadIntervalTimeout)
+)
+
+// newFile wraps descriptor fd.
+func newFile(fd int, name string) (*file, error) {
+	return poll.NewFile(uintptr(fd), name)
+}
+
+// File returns serial os.File struct.
+func (s *Serial) File() *poll.File {
+	return s.file()
+}
+
+func closeFd(fd int) error {
+	return syscall.Close(fd)
+}
+
+// dup duplicates fd with close on exec set.
+func dup(fd int) (int, error) {
+	syscall.ForkLock.RLock()
+	nfd, err := syscall.Dup(fd)
+	if err == nil {
+		syscall.CloseOnExec(nfd)
+	}
+	syscall.ForkLock.RUnlock()
+	if err != nil {
+		return -1, os.NewSyscallError("dup", err)
+	}
+	return nfd, nil
+}
+
+func setNonblock(fd int) error {
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		return os.NewSyscallError("setnonblock", err)
+	}
+	return nil
+}
+
+func (s *Serial) lock() error {
+	err := syscall.Flock(int(s.file().Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return ErrLocked
+	}
+	if err != nil {
+		return os.NewSyscallError("flock", err)
+	}
+	return nil
+}
+
+func (s *Serial) unlock() error {
+	if err := syscall.Flock(int(s.file().Fd()), syscall.LOCK_UN); err != nil {
+		return os.NewSyscallError("flock", err)
+	}
+	return nil
+}
+
+func termIxany(t *Termios, on bool) {
+	if on {
+		t.Iflag |= syscall.IXANY
+	} else {
+		t.Iflag &^= syscall.IXANY
+	}
+}
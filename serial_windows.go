@@ -0,0 +1,922 @@
+package serial
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Termios emulates POSIX terminal attributes on Windows, with the same flag values as Linux.
+// Line settings (speed, data bits, parity, stop bits, flow control) are translated to the
+// port DCB when applied, the rest are kept for reading back.
+type Termios struct {
+	Iflag  uint32
+	Oflag  uint32
+	Cflag  uint32
+	Lflag  uint32
+	Cc     [20]uint8
+	Ispeed uint32
+	Ospeed uint32
+}
+
+// Control character indices for Termios.CC and Termios.SetCC.
+// Windows only applies VSTART and VSTOP (software flow control characters).
+const (
+	VINTR    = 0  // Interrupt (Ex. 0x03)
+	VQUIT    = 1  // Quit
+	VERASE   = 2  // Erase character in canonical mode
+	VKILL    = 3  // Erase line in canonical mode
+	VEOF     = 4  // End of file in canonical mode
+	VTIME    = 5  // Non canonical read timeout in tenths of second (see SetReadIntervalTimeout)
+	VMIN     = 6  // Minimum bytes for a non canonical read (see SetReadIntervalTimeout)
+	VSTART   = 8  // XON, resumes output with software flow control (default 0x11)
+	VSTOP    = 9  // XOFF, stops output with software flow control (default 0x13)
+	VSUSP    = 10 // Suspend
+	VEOL     = 11 // Additional end of line
+	VREPRINT = 12 // Reprint line in canonical mode
+	VDISCARD = 13 // Toggle output discarding
+	VWERASE  = 14 // Erase word in canonical mode
+	VLNEXT   = 15 // Literal next
+	VEOL2    = 16 // Another additional end of line
+)
+
+// Termios flags, Linux values.
+const (
+	ignbrk  = 0x1
+	brkint  = 0x2
+	ignpar  = 0x4
+	parmrk  = 0x8
+	inpck   = 0x10
+	istrip  = 0x20
+	inlcr   = 0x40
+	igncr   = 0x80
+	icrnl   = 0x100
+	ixon    = 0x400
+	ixany   = 0x800
+	ixoff   = 0x1000
+	csize   = 0x30
+	cs5     = 0x0
+	cs6     = 0x10
+	cs7     = 0x20
+	cs8     = 0x30
+	cstopb  = 0x40
+	cread   = 0x80
+	parenb  = 0x100
+	parodd  = 0x200
+	hupcl   = 0x400
+	clocal  = 0x800
+	cmspar  = 0x40000000
+	crtscts = 0x80000000
+)
+
+// Standard rates (CBR_*), drivers may accept others.
+var baud = map[int]uint32{
+	110:    110,
+	300:    300,
+	600:    600,
+	1200:   1200,
+	2400:   2400,
+	4800:   4800,
+	9600:   9600,
+	14400:  14400,
+	19200:  19200,
+	38400:  38400,
+	57600:  57600,
+	115200: 115200,
+	128000: 128000,
+	256000: 256000,
+}
+
+var bits = map[int]uint32{
+	5: cs5,
+	6: cs6,
+	7: cs7,
+	8: cs8,
+}
+
+// Constants for modem control silgnals mask
+const (
+	DTR = 0x002
+	RTS = 0x004
+	CTS = 0x020
+	CAR = 0x040
+	RNG = 0x080
+	DSR = 0x100
+	DCD = CAR // Data carrier detect, alias of CAR
+	RI  = RNG // Ring indicator, alias of RNG
+)
+
+// icounter mirrors Linux serial_icounter_struct, Windows has no line counters.
+type icounter struct {
+	cts, dsr, rng, dcd          int32
+	rx, tx                      int32
+	frame, overrun, parity, brk int32
+	bufOverrun                  int32
+}
+
+// dcb mirrors the Win32 DCB structure.
+type dcb struct {
+	DCBlength  uint32
+	BaudRate   uint32
+	Flags      uint32
+	wReserved  uint16
+	XonLim     uint16
+	XoffLim    uint16
+	ByteSize   byte
+	Parity     byte
+	StopBits   byte
+	XonChar    byte
+	XoffChar   byte
+	ErrorChar  byte
+	EofChar    byte
+	EvtChar    byte
+	wReserved1 uint16
+}
+
+// DCB flags and values.
+const (
+	dcbBinary          = 0x0001
+	dcbParity          = 0x0002
+	dcbOutxCtsFlow     = 0x0004
+	dcbDtrControlMask  = 0x0030
+	dcbDtrControlOn    = 0x0010
+	dcbOutX            = 0x0100
+	dcbInX             = 0x0200
+	dcbRtsControlMask  = 0x3000
+	dcbRtsControlOn    = 0x1000
+	dcbRtsHandshake    = 0x2000
+	dcbAbortOnError    = 0x4000
+	noParity           = 0
+	oddParity          = 1
+	evenParity         = 2
+	markParity         = 3
+	spaceParity        = 4
+	oneStopBit         = 0
+	one5StopBits       = 1
+	twoStopBits        = 2
+	maxDword           = 0xFFFFFFFF
+	msCtsOn            = 0x10
+	msDsrOn            = 0x20
+	msRingOn           = 0x40
+	msRlsdOn           = 0x80
+	escSetRTS          = 3
+	escClrRTS          = 4
+	escSetDTR          = 5
+	escClrDTR          = 6
+	purgeTxAbort       = 0x1
+	purgeRxAbort       = 0x2
+	purgeTxClear       = 0x4
+	purgeRxClear       = 0x8
+	genericReadAccess  = 0x80000000
+	genericWriteAccess = 0x40000000
+)
+
+// commTimeouts mirrors the Win32 COMMTIMEOUTS structure.
+type commTimeouts struct {
+	ReadIntervalTimeout         uint32
+	ReadTotalTimeoutMultiplier  uint32
+	ReadTotalTimeoutConstant    uint32
+	WriteTotalTimeoutMultiplier uint32
+	WriteTotalTimeoutConstant   uint32
+}
+
+// comStat mirrors the Win32 COMSTAT structure.
+type comStat struct {
+	Flags    uint32
+	cbInQue  uint32
+	cbOutQue uint32
+}
+
+var (
+	procGetCommState       = kernel32.NewProc("GetCommState")
+	procSetCommState       = kernel32.NewProc("SetCommState")
+	procSetCommTimeouts    = kernel32.NewProc("SetCommTimeouts")
+	procEscapeCommFunction = kernel32.NewProc("EscapeCommFunction")
+	procGetCommModemStatus = kernel32.NewProc("GetCommModemStatus")
+	procClearCommError     = kernel32.NewProc("ClearCommError")
+	procPurgeComm          = kernel32.NewProc("PurgeComm")
+	procSetCommBreak       = kernel32.NewProc("SetCommBreak")
+	procClearCommBreak     = kernel32.NewProc("ClearCommBreak")
+)
+
+// comm calls Win32 comm function proc on serial handle.
+func (s *Serial) comm(proc *syscall.LazyProc, args ...uintptr) error {
+	r, _, e := proc.Call(append([]uintptr{s.file().Fd()}, args...)...)
+	if r == 0 {
+		return os.NewSyscallError(proc.Name, e)
+	}
+	return nil
+}
+
+// open opens COM port path ("COM3" or "\\.\COM3") for overlapped I/O, flags carry the
+// access mode (O_RDONLY, O_WRONLY or O_RDWR), other flags are ignored.
+func open(path string, flags int) (int, error) {
+	if flags&(syscall.O_CREAT|syscall.O_TRUNC|syscall.O_EXCL) != 0 {
+		return -1, errors.New("unsupported open flags")
+	}
+	if !strings.HasPrefix(path, `\\`) {
+		path = `\\.\` + path
+	}
+	var access uint32
+	switch flags & (syscall.O_WRONLY | syscall.O_RDWR) {
+	case syscall.O_RDONLY:
+		access = genericReadAccess
+	case syscall.O_WRONLY:
+		access = genericWriteAccess
+	default:
+		access = genericReadAccess | genericWriteAccess
+	}
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	h, err := syscall.CreateFile(name, access, 0, nil, syscall.OPEN_EXISTING,
+		syscall.FILE_ATTRIBUTE_NORMAL|syscall.FILE_FLAG_OVERLAPPED, 0)
+	if err != nil {
+		return -1, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	return int(h), nil
+}
+
+func (s *Serial) getDCB(d *dcb) error {
+	d.DCBlength = uint32(unsafe.Sizeof(*d))
+	return s.comm(procGetCommState, uintptr(unsafe.Pointer(d)))
+}
+
+// tcGetAttr reads line settings from the port DCB, other attributes are the ones
+// last applied.
+func (s *Serial) tcGetAttr(cfg *Termios) error {
+	var d dcb
+	if err := s.getDCB(&d); err != nil {
+		return err
+	}
+	f := s.file()
+	f.mu.Lock()
+	*cfg = f.tio
+	f.mu.Unlock()
+	cfg.Ispeed, cfg.Ospeed = d.BaudRate, d.BaudRate
+	cfg.Cflag &^= csize | cstopb | parenb | parodd | cmspar | crtscts
+	if bb, ok := bits[int(d.ByteSize)]; ok {
+		cfg.Cflag |= bb
+	}
+	if d.StopBits != oneStopBit {
+		cfg.Cflag |= cstopb
+	}
+	switch d.Parity {
+	case oddParity:
+		cfg.Cflag |= parenb | parodd
+	case evenParity:
+		cfg.Cflag |= parenb
+	case markParity:
+		cfg.Cflag |= parenb | cmspar | parodd
+	case spaceParity:
+		cfg.Cflag |= parenb | cmspar
+	}
+	if d.Flags&dcbOutxCtsFlow != 0 {
+		cfg.Cflag |= crtscts
+	}
+	cfg.Iflag &^= ixon | ixoff
+	if d.Flags&dcbOutX != 0 {
+		cfg.Iflag |= ixon
+	}
+	if d.Flags&dcbInX != 0 {
+		cfg.Iflag |= ixoff
+	}
+	cfg.Cc[VSTART], cfg.Cc[VSTOP] = d.XonChar, d.XoffChar
+	return nil
+}
+
+// tcSetAttr applies line settings to the port DCB. Speed 0 hangs up (drops DTR) keeping
+// the current rate, as on POSIX systems.
+func (s *Serial) tcSetAttr(cfg *Termios) error {
+	var d dcb
+	if err := s.getDCB(&d); err != nil {
+		return err
+	}
+	f := s.file()
+	f.mu.Lock()
+	ctrl := f.ctrl
+	f.mu.Unlock()
+	if cfg.Ospeed == 0 {
+		ctrl &^= DTR
+	} else {
+		d.BaudRate = cfg.Ospeed
+	}
+	for k, v := range bits {
+		if v == cfg.Cflag&csize {
+			d.ByteSize = byte(k)
+		}
+	}
+	d.StopBits = oneStopBit
+	if cfg.Cflag&cstopb != 0 {
+		d.StopBits = twoStopBits
+		if cfg.Cflag&csize == cs5 {
+			d.StopBits = one5StopBits
+		}
+	}
+	switch {
+	case cfg.Cflag&parenb == 0:
+		d.Parity = noParity
+	case cfg.Cflag&cmspar != 0 && cfg.Cflag&parodd != 0:
+		d.Parity = markParity
+	case cfg.Cflag&cmspar != 0:
+		d.Parity = spaceParity
+	case cfg.Cflag&parodd != 0:
+		d.Parity = oddParity
+	default:
+		d.Parity = evenParity
+	}
+	d.Flags &^= dcbParity | dcbOutxCtsFlow | dcbDtrControlMask | dcbOutX | dcbInX |
+		dcbRtsControlMask | dcbAbortOnError
+	d.Flags |= dcbBinary
+	if d.Parity != noParity {
+		d.Flags |= dcbParity
+	}
+	if ctrl&DTR != 0 {
+		d.Flags |= dcbDtrControlOn
+	}
+	switch {
+	case cfg.Cflag&crtscts != 0:
+		d.Flags |= dcbOutxCtsFlow | dcbRtsHandshake
+	case ctrl&RTS != 0:
+		d.Flags |= dcbRtsControlOn
+	}
+	if cfg.Iflag&ixon != 0 {
+		d.Flags |= dcbOutX
+	}
+	if cfg.Iflag&ixoff != 0 {
+		d.Flags |= dcbInX
+	}
+	d.XonChar, d.XoffChar = cfg.Cc[VSTART], cfg.Cc[VSTOP]
+	if err := s.comm(procSetCommState, uintptr(unsafe.Pointer(&d))); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.tio = *cfg
+	f.ctrl = ctrl
+	f.mu.Unlock()
+	return nil
+}
+
+// init sets default params and read timeouts making ReadFile return as soon as some bytes
+// are received (deadlines are handled by File).
+func (s *Serial) init() error {
+	to := commTimeouts{
+		ReadIntervalTimeout:        maxDword,
+		ReadTotalTimeoutMultiplier: maxDword,
+		ReadTotalTimeoutConstant:   maxDword - 1,
+	}
+	if err := s.comm(procSetCommTimeouts, uintptr(unsafe.Pointer(&to))); err != nil {
+		return err
+	}
+	f := s.file()
+	f.mu.Lock()
+	f.ctrl = DTR | RTS
+	f.mu.Unlock()
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	t.Iflag = (0)
+	t.Oflag = (0)
+	t.Lflag = (0)
+	t.Cflag = (bits[DefaultBits] | clocal | hupcl | cread)
+	t.Cc[VMIN] = 1
+	t.Cc[VTIME] = 0
+	t.Cc[VSTART] = XON
+	t.Cc[VSTOP] = XOFF
+	t.Ispeed = baud[DefaultSpeed]
+	t.Ospeed = baud[DefaultSpeed]
+	if err := s.tcSetAttr(&t); err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateAttr applies fn to current serial attributes and commits them with a single tcSetAttr.
+// Nothing is committed if fn fails. Concurrent updates are serialized by s.cmu.
+func (s *Serial) updateAttr(fn func(t *Termios) error) error {
+	s.cmu.Lock()
+	defer s.cmu.Unlock()
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	if err := fn(&t); err != nil {
+		return err
+	}
+	if err := s.tcSetAttr(&t); err != nil {
+		return err
+	}
+	s.attr = t.Clone()
+	return nil
+}
+
+func termBits(t *Termios, b int) error {
+	bb, ok := bits[b]
+	if !ok {
+		return errors.New("Usupported bits number")
+	}
+	t.Cflag &^= csize
+	t.Cflag |= bb
+	return nil
+}
+
+// termSpeed sets speed b. The DCB takes the rate itself, non standard rates are
+// accepted or rejected by the driver.
+func termSpeed(t *Termios, b int) error {
+	if err := checkSpeed(b); err != nil {
+		return err
+	}
+	t.Ispeed = uint32(b)
+	t.Ospeed = uint32(b)
+	return nil
+}
+
+// termGetSpeed returns speed set in t.
+func termGetSpeed(t *Termios) int {
+	return int(t.Ospeed)
+}
+
+func termParity(t *Termios, mode int) error {
+	switch mode {
+	case PAR_NONE:
+		t.Cflag &^= parenb | cmspar
+	case PAR_EVEN:
+		t.Cflag |= parenb
+		t.Cflag &^= parodd | cmspar
+	case PAR_ODD:
+		t.Cflag |= parenb
+		t.Cflag |= parodd
+		t.Cflag &^= cmspar
+	case PAR_MARK:
+		t.Cflag |= parenb | cmspar | parodd
+	case PAR_SPACE:
+		t.Cflag |= parenb | cmspar
+		t.Cflag &^= parodd
+	default:
+		return errors.New("invalid parity mode")
+	}
+	return nil
+}
+
+func termStopBits2(t *Termios, two bool) {
+	if two {
+		t.Cflag |= cstopb
+	} else {
+		t.Cflag &^= cstopb
+	}
+}
+
+// termStopBits sets stop bits 1, 2 or STOP_1_5. CSTOPB means 1.5 stop bits with 5 data bits,
+// so STOP_1_5 requires 5 data bits to be already set.
+func termStopBits(t *Termios, stop int) error {
+	switch stop {
+	case 1, 2:
+		termStopBits2(t, stop == 2)
+	case STOP_1_5:
+		if t.Cflag&csize != cs5 {
+			return errors.New("1.5 stop bits require 5 data bits")
+		}
+		termStopBits2(t, true)
+	default:
+		return errors.New("Invalid stop bits number")
+	}
+	return nil
+}
+
+func termHwFlowCtrl(t *Termios, hw bool) {
+	if hw {
+		t.Cflag |= crtscts
+	} else {
+		t.Cflag &^= crtscts
+	}
+}
+
+func termSwFlowCtrl(t *Termios, sw bool) {
+	if sw {
+		t.Iflag |= (ixon | ixoff | ixany)
+	} else {
+		t.Iflag &^= (ixon | ixoff | ixany)
+	}
+}
+
+// termIxany sets IXANY, kept but not applied: Windows only resumes output on XON.
+func termIxany(t *Termios, on bool) {
+	if on {
+		t.Iflag |= ixany
+	} else {
+		t.Iflag &^= ixany
+	}
+}
+
+func (s *Serial) setBits(b int) error {
+	return s.updateAttr(func(t *Termios) error { return termBits(t, b) })
+}
+
+func (s *Serial) setSpeed(b int) error {
+	if err := s.updateAttr(func(t *Termios) error { return termSpeed(t, b) }); err != nil {
+		return err
+	}
+	if _, ok := baud[b]; ok || b == 0 {
+		return nil
+	}
+	// Drivers round arbitrary rates to what the hardware can do, check the result.
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return err
+	}
+	if got := termGetSpeed(&t); !speedMatch(b, got) {
+		return &SpeedMismatchError{Requested: b, Actual: got}
+	}
+	return nil
+}
+
+func (s *Serial) setParity(mode int) error {
+	return s.updateAttr(func(t *Termios) error { return termParity(t, mode) })
+}
+
+func (s *Serial) setStopBits(stop int) error {
+	return s.updateAttr(func(t *Termios) error { return termStopBits(t, stop) })
+}
+
+func (s *Serial) setHwFlowCtrl(hw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termHwFlowCtrl(t, hw)
+		return nil
+	})
+}
+
+func (s *Serial) setSwFlowCtrl(sw bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termSwFlowCtrl(t, sw)
+		return nil
+	})
+}
+
+func (s *Serial) flowCtrl() (hw, sw bool, err error) {
+	var t Termios
+	if err = s.tcGetAttr(&t); err != nil {
+		return
+	}
+	return t.Cflag&crtscts != 0, t.Iflag&ixon != 0, nil
+}
+
+func termLocal(t *Termios, local bool) {
+	if local {
+		t.Cflag |= clocal
+	} else {
+		t.Cflag &^= clocal
+	}
+}
+
+// setLocal keeps the flag only, Windows never waits for carrier.
+func (s *Serial) setLocal(local bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		termLocal(t, local)
+		return nil
+	})
+}
+
+// setInputCheck is unsupported when enabling, Windows can't mark bytes received with errors.
+func (s *Serial) setInputCheck(on bool) error {
+	if on {
+		return ErrUnsupported
+	}
+	return s.updateAttr(func(t *Termios) error {
+		t.Iflag &^= inpck | parmrk
+		return nil
+	})
+}
+
+// setReadTimeout keeps VMIN and VTIME only, read timing is emulated (see SetReadIntervalTimeout).
+func (s *Serial) setReadTimeout(vmin int, vtime time.Duration) error {
+	return s.updateAttr(func(t *Termios) error {
+		t.Cc[VMIN] = uint8(vmin)
+		t.Cc[VTIME] = uint8(vtime / (time.Second / 10))
+		return nil
+	})
+}
+
+// setCanonical is unsupported, Windows drivers have no line discipline.
+func (s *Serial) setCanonical(erase, kill byte) error {
+	return ErrUnsupported
+}
+
+// setRaw succeeds for raw mode, the only one Windows drivers have.
+func (s *Serial) setRaw(raw bool) error {
+	if !raw {
+		return ErrUnsupported
+	}
+	return s.updateAttr(func(t *Termios) error {
+		t.Iflag &^= ignbrk | brkint | parmrk | istrip | inlcr | igncr | icrnl
+		t.Oflag = 0
+		t.Lflag = 0
+		t.Cc[VMIN] = 1
+		t.Cc[VTIME] = 0
+		return nil
+	})
+}
+
+// setHup keeps the flag only, Windows drivers drop DTR on close.
+func (s *Serial) setHup(hup bool) error {
+	return s.updateAttr(func(t *Termios) error {
+		if hup {
+			t.Cflag |= hupcl
+		} else {
+			t.Cflag &^= hupcl
+		}
+		return nil
+	})
+}
+
+// setExclusive only succeeds for exclusive mode, COM ports can't be shared on Windows.
+func (s *Serial) setExclusive(excl bool) error {
+	if !excl {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// setCtrlBit sets DTR and RTS lines in ctr, other lines are inputs.
+func (s *Serial) setCtrlBit(ctr int, level bool) error {
+	f := s.file()
+	f.mu.Lock()
+	cur := f.ctrl
+	f.mu.Unlock()
+	if level {
+		return s.setCtrl(cur | ctr)
+	}
+	return s.setCtrl(cur &^ ctr)
+}
+
+// getCtrl returns modem input lines, along with DTR and RTS as last set.
+func (s *Serial) getCtrl() (int, error) {
+	var st uint32
+	if err := s.comm(procGetCommModemStatus, uintptr(unsafe.Pointer(&st))); err != nil {
+		return 0, err
+	}
+	f := s.file()
+	f.mu.Lock()
+	ctr := f.ctrl
+	f.mu.Unlock()
+	if st&msCtsOn != 0 {
+		ctr |= CTS
+	}
+	if st&msDsrOn != 0 {
+		ctr |= DSR
+	}
+	if st&msRingOn != 0 {
+		ctr |= RNG
+	}
+	if st&msRlsdOn != 0 {
+		ctr |= CAR
+	}
+	return ctr, nil
+}
+
+func (s *Serial) setCtrl(ctr int) error {
+	dtr, rts := uintptr(escClrDTR), uintptr(escClrRTS)
+	if ctr&DTR != 0 {
+		dtr = escSetDTR
+	}
+	if ctr&RTS != 0 {
+		rts = escSetRTS
+	}
+	if err := s.comm(procEscapeCommFunction, dtr); err != nil {
+		return err
+	}
+	if err := s.comm(procEscapeCommFunction, rts); err != nil {
+		return err
+	}
+	f := s.file()
+	f.mu.Lock()
+	f.ctrl = ctr & (DTR | RTS)
+	f.mu.Unlock()
+	return nil
+}
+
+func (s *Serial) comStat() (comStat, error) {
+	var errs uint32
+	var st comStat
+	err := s.comm(procClearCommError, uintptr(unsafe.Pointer(&errs)), uintptr(unsafe.Pointer(&st)))
+	return st, err
+}
+
+func (s *Serial) inpWaiting() (int, error) {
+	st, err := s.comStat()
+	return int(st.cbInQue), err
+}
+
+func (s *Serial) outWaiting() (int, error) {
+	st, err := s.comStat()
+	return int(st.cbOutQue), err
+}
+
+func (s *Serial) flush(mode int) error {
+	var v uintptr
+	switch mode {
+	case FLUSH_I:
+		v = purgeRxAbort | purgeRxClear
+	case FLUSH_O:
+		v = purgeTxAbort | purgeTxClear
+	case FLUSH_IO:
+		v = purgeRxAbort | purgeRxClear | purgeTxAbort | purgeTxClear
+	default:
+		return errors.New("invalid flush mode")
+	}
+	return s.comm(procPurgeComm, v)
+}
+
+// getICount is unsupported, Windows drivers don't count line events.
+func (s *Serial) getICount(c *icounter) error {
+	return ErrUnsupported
+}
+
+// waitCtrl is unsupported, modem lines are polled.
+func (s *Serial) waitCtrl(mask int) error {
+	return ErrUnsupported
+}
+
+func (s *Serial) ctrlCount(mask int) (n int, ok bool) {
+	return 0, false
+}
+
+func (s *Serial) tcDrain() error {
+	if err := syscall.FlushFileBuffers(syscall.Handle(s.file().Fd())); err != nil {
+		return os.NewSyscallError("FlushFileBuffers", err)
+	}
+	return nil
+}
+
+// tcSendBreak sends a 0.4 seconds break, like Darwin.
+func (s *Serial) tcSendBreak() error {
+	if err := s.setBreak(true); err != nil {
+		return err
+	}
+	time.Sleep(400 * time.Millisecond)
+	return s.setBreak(false)
+}
+
+func (s *Serial) setBreak(on bool) error {
+	if on {
+		return s.comm(procSetCommBreak)
+	}
+	return s.comm(procClearCommBreak)
+}
+
+func termControlFlags(t *Termios) ControlFlagsInfo {
+	var cf ControlFlagsInfo
+	for k, v := range bits {
+		if v == t.Cflag&csize {
+			cf.DataBits = k
+			break
+		}
+	}
+	cf.StopBits = 1
+	if t.Cflag&cstopb != 0 {
+		cf.StopBits = 2
+		if t.Cflag&csize == cs5 {
+			cf.StopBits = STOP_1_5
+		}
+	}
+	switch {
+	case t.Cflag&parenb == 0:
+		cf.Parity = PAR_NONE
+	case t.Cflag&cmspar != 0 && t.Cflag&parodd != 0:
+		cf.Parity = PAR_MARK
+	case t.Cflag&cmspar != 0:
+		cf.Parity = PAR_SPACE
+	case t.Cflag&parodd != 0:
+		cf.Parity = PAR_ODD
+	default:
+		cf.Parity = PAR_EVEN
+	}
+	cf.HwFlow = t.Cflag&crtscts != 0
+	cf.Local = t.Cflag&clocal != 0
+	cf.HangupOnClose = t.Cflag&hupcl != 0
+	return cf
+}
+
+func (s *Serial) controlFlags() (ControlFlagsInfo, error) {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return ControlFlagsInfo{}, err
+	}
+	return termControlFlags(&t), nil
+}
+
+func (s *Serial) speed() (int, error) {
+	var t Termios
+	if err := s.tcGetAttr(&t); err != nil {
+		return 0, err
+	}
+	return termGetSpeed(&t), nil
+}
+
+// frameBits returns current speed and the number of bits per transmitted character.
+func (s *Serial) frameBits() (speed int, nbits int, err error) {
+	var t Termios
+	if err = s.tcGetAttr(&t); err != nil {
+		return
+	}
+	speed = termGetSpeed(&t)
+	cf := termControlFlags(&t)
+	stop := cf.StopBits
+	if stop == STOP_1_5 {
+		stop = 2 // Round up, timing based on it errs on the safe side
+	}
+	nbits = 1 + cf.DataBits + stop // Start, data and stop bits
+	if cf.Parity != PAR_NONE {
+		nbits++
+	}
+	return
+}
+
+// txEmpty reports whether the transmitter is empty, Windows drivers can't tell (ok is false).
+func (s *Serial) txEmpty() (empty bool, ok bool) {
+	return false, false
+}
+
+// readv reads into bufs the bytes already received, it returns syscall.EAGAIN when no data
+// is available. COM ports have no scatter read, so it's a single ReadFile into a temporary
+// buffer copied to bufs.
+func (s *Serial) readv(bufs [][]byte) (int, error) {
+	avail, err := s.inpWaiting()
+	if err != nil {
+		return 0, err
+	}
+	if avail == 0 {
+		return 0, syscall.EAGAIN
+	}
+	if l := vecLen(bufs); avail > l {
+		avail = l
+	}
+	tmp := make([]byte, avail)
+	n, err := s.file().Read(tmp)
+	vecScatter(bufs, tmp[:n])
+	return n, err
+}
+
+// waitIn waits up to d for input on serial file and reports whether it's ready.
+// The input queue is polled, Windows has no readiness wait for COM ports outside
+// overlapped I/O.
+func (s *Serial) waitIn(d time.Duration) (bool, error) {
+	dl := time.Now().Add(d)
+	for {
+		n, err := s.inpWaiting()
+		if n > 0 || err != nil {
+			return n > 0, err
+		}
+		left := time.Until(dl)
+		if left <= 0 {
+			return false, nil
+		}
+		time.Sleep(pollDelay(time.Millisecond, left))
+	}
+}
+
+// makeControllingTerminal is unsupported, Windows has no controlling terminals.
+func (s *Serial) makeControllingTerminal() error {
+	return ErrUnsupported
+}
+
+// setRS485 is unsupported, Windows has no generic RS-485 configuration.
+func (s *Serial) setRS485(cfg RS485Config) error {
+	return ErrUnsupported
+}
+
+func (s *Serial) getRS485() (RS485Config, error) {
+	return RS485Config{}, ErrUnsupported
+}
+
+// dup duplicates handle fd, not inheritable.
+func dup(fd int) (int, error) {
+	p, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return -1, os.NewSyscallError("GetCurrentProcess", err)
+	}
+	var h syscall.Handle
+	if err := syscall.DuplicateHandle(p, syscall.Handle(fd), p, &h, 0, false,
+		syscall.DUPLICATE_SAME_ACCESS); err != nil {
+		return -1, os.NewSyscallError("DuplicateHandle", err)
+	}
+	return int(h), nil
+}
+
+// setNonblock does nothing, File uses overlapped I/O.
+func setNonblock(fd int) error {
+	return nil
+}
+
+// lock does nothing, COM ports are opened exclusively on Windows.
+func (s *Serial) lock() error {
+	return nil
+}
+
+func (s *Serial) unlock() error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package serial
+
+import "errors"
+
+// SLIP (RFC 1055) special characters.
+const (
+	SLIP_END     = 0xc0 // Frame delimiter
+	SLIP_ESC     = 0xdb // Escape
+	SLIP_ESC_END = 0xdc // Escaped END
+	SLIP_ESC_ESC = 0xdd // Escaped ESC
+)
+
+// ErrSLIPEscape is returned when a received SLIP frame has a malformed escape sequence.
+var ErrSLIPEscape = errors.New("malformed SLIP escape")
+
+// SLIPEncode encodes p as a SLIP frame, including leading and trailing END delimiters.
+func SLIPEncode(p []byte) []byte {
+	out := make([]byte, 0, len(p)+len(p)/8+2)
+	out = append(out, SLIP_END)
+	for _, b := range p {
+		switch b {
+		case SLIP_END:
+			out = append(out, SLIP_ESC, SLIP_ESC_END)
+		case SLIP_ESC:
+			out = append(out, SLIP_ESC, SLIP_ESC_ESC)
+		default:
+			out = append(out, b)
+		}
+	}
+	return append(out, SLIP_END)
+}
+
+// WriteSLIP writes pkt as a single SLIP frame.
+func (s *Serial) WriteSLIP(pkt []byte) error {
+	_, err := s.WriteFull(SLIPEncode(pkt))
+	return err
+}
+
+// ReadSLIP reads a SLIP frame and returns it decoded. Leading END bytes (empty frames) are skipped.
+// Frames longer than max decoded bytes (0 = unlimited) or with a malformed escape sequence are
+// discarded up to the next END before returning ErrFrameTooLong or ErrSLIPEscape.
+func (s *Serial) ReadSLIP(max int) ([]byte, error) {
+	var buf []byte
+	var ferr error
+	esc := false
+	for {
+		b, err := s.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == SLIP_END {
+			if ferr == nil && esc {
+				ferr = ErrSLIPEscape
+			}
+			if ferr != nil {
+				return nil, ferr
+			}
+			if len(buf) > 0 {
+				return buf, nil
+			}
+			continue
+		}
+		if ferr != nil {
+			continue
+		}
+		if esc {
+			esc = false
+			switch b {
+			case SLIP_ESC_END:
+				b = SLIP_END
+			case SLIP_ESC_ESC:
+				b = SLIP_ESC
+			default:
+				ferr = ErrSLIPEscape
+				continue
+			}
+		} else if b == SLIP_ESC {
+			esc = true
+			continue
+		}
+		if max > 0 && len(buf) >= max {
+			ferr = ErrFrameTooLong
+			continue
+		}
+		buf = append(buf, b)
+	}
+}
@@ -0,0 +1,75 @@
+package serial
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSLIPEncode(t *testing.T) {
+	tests := []struct {
+		in, out []byte
+	}{
+		{nil, []byte{0xc0, 0xc0}},
+		{[]byte("abc"), []byte{0xc0, 'a', 'b', 'c', 0xc0}},
+		{[]byte{0xc0}, []byte{0xc0, 0xdb, 0xdc, 0xc0}},
+		{[]byte{0xdb}, []byte{0xc0, 0xdb, 0xdd, 0xc0}},
+		{[]byte{0x01, 0xc0, 0xdb, 0xdc, 0xdd}, []byte{0xc0, 0x01, 0xdb, 0xdc, 0xdb, 0xdd, 0xdc, 0xdd, 0xc0}},
+	}
+	for _, tt := range tests {
+		if got := SLIPEncode(tt.in); !bytes.Equal(got, tt.out) {
+			t.Errorf("SLIPEncode(% x) = % x, want % x", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestReadWriteSLIP(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	frames := [][]byte{[]byte("hello"), {0xc0, 0xdb, 0x00}, seq(0x00, 0xff)}
+	for _, f := range frames {
+		if err := a.WriteSLIP(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range frames {
+		got, err := b.ReadSLIP(0)
+		if err != nil || !bytes.Equal(got, f) {
+			t.Fatalf("ReadSLIP = % x, %v, want % x", got, err, f)
+		}
+	}
+}
+
+func TestReadSLIPSkipsEmpty(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	a.Write([]byte{0xc0, 0xc0, 0xc0, 'x', 0xc0})
+	if got, err := b.ReadSLIP(0); err != nil || string(got) != "x" {
+		t.Fatalf("ReadSLIP = %q, %v, want \"x\"", got, err)
+	}
+}
+
+func TestReadSLIPErrors(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	tests := []struct {
+		raw []byte
+		max int
+		err error
+	}{
+		{[]byte{0xc0, 'a', 0xdb, 'b', 'c', 0xc0}, 0, ErrSLIPEscape},
+		{[]byte{0xc0, 'a', 0xdb, 0xc0}, 0, ErrSLIPEscape},
+		{[]byte{0xc0, 'a', 'b', 'c', 'd', 'e', 0xc0}, 4, ErrFrameTooLong},
+	}
+	for _, tt := range tests {
+		a.Write(tt.raw)
+		if _, err := b.ReadSLIP(tt.max); err != tt.err {
+			t.Fatalf("ReadSLIP(% x) error = %v, want %v", tt.raw, err, tt.err)
+		}
+		// The bad frame was discarded, the next one is read intact.
+		a.WriteSLIP([]byte("ok"))
+		if got, err := b.ReadSLIP(tt.max); err != nil || string(got) != "ok" {
+			t.Fatalf("ReadSLIP after % x = %q, %v, want \"ok\"", tt.raw, got, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrSpeedMismatch is matched (errors.Is) by a SpeedMismatchError.
+var ErrSpeedMismatch = errors.New("baud rate mismatch")
+
+// ErrSpeedUnsupported is returned setting a baud rate no platform path can set
+// (negative or not fitting the 32 bit rate field of termios and the Windows DCB).
+var ErrSpeedUnsupported = errors.New("baud rate not supported on this platform")
+
+// SpeedMismatchError is returned when the driver applies a rate other than the requested one.
+type SpeedMismatchError struct {
+	Requested int // Requested baud rate
+	Actual    int // Baud rate applied by the driver
+}
+
+func (e *SpeedMismatchError) Error() string {
+	return fmt.Sprintf("baud rate %d not supported by driver (got %d)", e.Requested, e.Actual)
+}
+
+// Is reports whether target is ErrSpeedMismatch.
+func (e *SpeedMismatchError) Is(target error) bool {
+	return target == ErrSpeedMismatch
+}
+
+// speedMatch reports whether actual rate is within 2% of requested rate.
+func speedMatch(requested, actual int) bool {
+	return actual >= requested-requested/50 && actual <= requested+requested/50
+}
+
+// SetSpeedVerified sets serial speed like SetSpeed and reads it back, returning a
+// SpeedMismatchError (see ErrSpeedMismatch) if the driver applied a rate more than 2% off.
+// Only rounding reported by the driver through termios can be detected.
+func (s *Serial) SetSpeedVerified(speed int) error {
+	if err := s.SetSpeed(speed); err != nil {
+		return err
+	}
+	got, err := s.Speed()
+	if err != nil {
+		return err
+	}
+	if !speedMatch(speed, got) {
+		return s.wrapErr("SetSpeed", &SpeedMismatchError{Requested: speed, Actual: got})
+	}
+	return nil
+}
+
+// SupportedSpeeds returns the standard baud rates known on this platform, in ascending order.
+// Other rates may still work as custom rates when the driver supports them (see SetSpeed).
+func SupportedSpeeds() []int {
+	speeds := make([]int, 0, len(baud))
+	for b := range baud {
+		if b > 0 {
+			speeds = append(speeds, b)
+		}
+	}
+	sort.Ints(speeds)
+	return speeds
+}
+
+// nearestSpeed returns the standard baud rate closest to b.
+func nearestSpeed(b int) int {
+	best := 0
+	for _, sp := range SupportedSpeeds() {
+		if best == 0 || abs(sp-b) < abs(best-b) {
+			best = sp
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// checkSpeed rejects rates b the platform can't set at all, naming the nearest standard one.
+// It's checked by termSpeed, so every speed setting path applies it. Other rates are
+// standard or left to the custom rate path, the driver refusing them.
+func checkSpeed(b int) error {
+	if b >= 0 && uint64(b) <= math.MaxUint32 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d (nearest standard rate is %d)", ErrSpeedUnsupported, b, nearestSpeed(b))
+}
+
+// speedError adds the nearest standard rate to err, returned setting non standard rate b.
+func speedError(b int, err error) error {
+	if _, ok := baud[b]; ok || err == nil || errors.Is(err, ErrSpeedUnsupported) {
+		return err
+	}
+	return fmt.Errorf("%w (nearest standard rate is %d)", err, nearestSpeed(b))
+}
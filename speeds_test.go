@@ -0,0 +1,54 @@
+package serial
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestCheckSpeed(t *testing.T) {
+	// Standard rates and custom ones are left to the driver on every platform.
+	rates := append(SupportedSpeeds(), 0, 31250, 128000, 250000, 256000, 460800, 921600, 4000000)
+	for _, b := range rates {
+		if err := checkSpeed(b); err != nil {
+			t.Errorf("checkSpeed(%d) = %v, want nil", b, err)
+		}
+	}
+	bad := []int{-1, -9600}
+	if strconv.IntSize == 64 {
+		big := int64(math.MaxUint32 + 1)
+		bad = append(bad, int(big), math.MaxInt)
+	}
+	for _, b := range bad {
+		if err := checkSpeed(b); !errors.Is(err, ErrSpeedUnsupported) {
+			t.Errorf("checkSpeed(%d) = %v, want ErrSpeedUnsupported", b, err)
+		}
+	}
+}
+
+func TestSetSpeedInvalidKeepsPort(t *testing.T) {
+	a, _ := openTestPair(t)
+	if err := a.SetSpeed(19200); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetSpeed(-1); !errors.Is(err, ErrSpeedUnsupported) {
+		t.Fatalf("SetSpeed(-1) error = %v, want ErrSpeedUnsupported", err)
+	}
+	if err := a.ApplyConfig(Config{Speed: -1}); !errors.Is(err, ErrSpeedUnsupported) {
+		t.Fatalf("ApplyConfig speed -1 error = %v, want ErrSpeedUnsupported", err)
+	}
+	if got, err := a.Speed(); err != nil || got != 19200 {
+		t.Fatalf("Speed = %d, %v, want 19200", got, err)
+	}
+}
+
+func TestSetSpeedCustom(t *testing.T) {
+	a, _ := openTestPair(t)
+	for _, b := range []int{128000, 460800, 250000} {
+		err := a.SetSpeed(b)
+		if errors.Is(err, ErrSpeedUnsupported) {
+			t.Errorf("SetSpeed(%d) rejected before trying the driver: %v", b, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package serial
+
+import "sync/atomic"
+
+// SerialStats holds serial I/O counters since open (or last ResetStats).
+type SerialStats struct {
+	BytesRead    uint64 // Bytes received
+	BytesWritten uint64 // Bytes written
+	ReadErrors   uint64 // Failed reads, timeouts excluded
+	WriteErrors  uint64 // Failed writes, timeouts excluded
+	Timeouts     uint64 // Reads and writes that timed out
+}
+
+type counters struct {
+	read, written atomic.Uint64
+	rerrs, werrs  atomic.Uint64
+	timeouts      atomic.Uint64
+}
+
+// fail classifies I/O error err, counting it on errs or as timeout.
+func (c *counters) fail(errs *atomic.Uint64, err error) {
+	switch {
+	case err == nil:
+	case err == ErrTimeout:
+		c.timeouts.Add(1)
+	default:
+		errs.Add(1)
+	}
+}
+
+// Stats returns serial I/O counters.
+// Counters are updated atomically, so Stats is cheap enough to be polled by a monitor.
+func (s *Serial) Stats() SerialStats {
+	return SerialStats{
+		BytesRead:    s.ctr.read.Load(),
+		BytesWritten: s.ctr.written.Load(),
+		ReadErrors:   s.ctr.rerrs.Load(),
+		WriteErrors:  s.ctr.werrs.Load(),
+		Timeouts:     s.ctr.timeouts.Load(),
+	}
+}
+
+// ResetStats sets all serial I/O counters to zero.
+func (s *Serial) ResetStats() {
+	s.ctr.read.Store(0)
+	s.ctr.written.Store(0)
+	s.ctr.rerrs.Store(0)
+	s.ctr.werrs.Store(0)
+	s.ctr.timeouts.Store(0)
+}
+
+// ErrorCounters holds line error counts kept by the driver since the port was opened
+// by the first process (not reset by ResetStats).
+type ErrorCounters struct {
+	Overrun    int // Bytes lost because the UART received faster than they were read from it
+	BufOverrun int // Bytes lost because the kernel input buffer was full
+	Framing    int // Framing errors
+	Parity     int // Parity errors
+	Break      int // Breaks received
+}
+
+// ErrorCounters returns line error counters (Linux TIOCGICOUNT). Rising overrun counts mean
+// received data is being lost: use flow control or a lower speed.
+// ErrUnsupported is returned on platforms without line counters.
+func (s *Serial) ErrorCounters() (ErrorCounters, error) {
+	var c icounter
+	if err := s.getICount(&c); err != nil {
+		return ErrorCounters{}, err
+	}
+	return ErrorCounters{
+		Overrun:    int(c.overrun),
+		BufOverrun: int(c.bufOverrun),
+		Framing:    int(c.frame),
+		Parity:     int(c.parity),
+		Break:      int(c.brk),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStrict is wrapped by errors returned for operations rejected in strict mode.
+var ErrStrict = errors.New("rejected in strict mode")
+
+func strictError(msg string) error {
+	return fmt.Errorf("%w: %s", ErrStrict, msg)
+}
+
+// SetStrict enables or disables strict mode. In strict mode commonly misused
+// operations return an error wrapping ErrStrict instead of silently proceeding:
+//   - Read with a zero-length slice.
+//   - Enabling hardware and software flow control at the same time.
+//   - Setting speed 0 (use HangUp to drop the line on purpose).
+//   - Raw Read while an interrupted ReadLine left a partial line pending.
+func (s *Serial) SetStrict(strict bool) {
+	s.strict.Store(strict)
+}
+
+// HangUp sets speed to 0, which makes the driver drop DTR (modem hang up).
+func (s *Serial) HangUp() error {
+	return s.wrapErr("HangUp", s.setSpeed(0))
+}
+
+// checkRead validates a raw read in strict mode.
+func (s *Serial) checkRead(b []byte) error {
+	if !s.strict.Load() {
+		return nil
+	}
+	if len(b) == 0 {
+		return strictError("zero-length read")
+	}
+	s.bmu.Lock()
+	defer s.bmu.Unlock()
+	if s.pending != "" {
+		return strictError("raw read with a partial line pending")
+	}
+	return nil
+}
+
+// checkFlowCtrl validates in strict mode a flow control change, fn returns the resulting
+// hardware and software flow control state given the current one.
+func (s *Serial) checkFlowCtrl(fn func(hw, sw bool) (bool, bool)) error {
+	if !s.strict.Load() {
+		return nil
+	}
+	curHw, curSw, err := s.flowCtrl()
+	if err != nil {
+		return err
+	}
+	if hw, sw := fn(curHw, curSw); hw && sw {
+		return strictError("hardware and software flow control enabled together")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package serial
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jaracil/poll"
+)
+
+const sysTTY = "/sys/class/tty"
+
+// sysfsRead returns the trimmed content of a sysfs attribute file, "" on error.
+func sysfsRead(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// sysfsDevice returns the sysfs device directory of tty device path (Ex. "/dev/ttyUSB0").
+func sysfsDevice(path string) (string, error) {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+	return filepath.EvalSymlinks(filepath.Join(sysTTY, filepath.Base(path), "device"))
+}
+
+// sysfsUp walks up from dir until it finds a directory holding attribute file name.
+func sysfsUp(dir, name string) string {
+	for ; dir != "/" && dir != "." && strings.HasPrefix(dir, "/sys/"); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
+
+// bulkInSize returns the largest bulk in endpoint wMaxPacketSize of USB interface dir, 0 if none.
+func bulkInSize(dir string) int {
+	eps, _ := filepath.Glob(filepath.Join(dir, "ep_*"))
+	size := 0
+	for _, ep := range eps {
+		if sysfsRead(filepath.Join(ep, "type")) != "Bulk" || sysfsRead(filepath.Join(ep, "direction")) != "in" {
+			continue
+		}
+		if v, err := strconv.ParseInt(sysfsRead(filepath.Join(ep, "wMaxPacketSize")), 16, 32); err == nil && int(v) > size {
+			size = int(v)
+		}
+	}
+	return size
+}
+
+func (s *Serial) usbBulkSize() (int, error) {
+	dev, err := sysfsDevice(s.Name())
+	if err != nil {
+		return 0, ErrUnsupported
+	}
+	intf := sysfsUp(dev, "bInterfaceNumber")
+	if intf == "" {
+		return 0, ErrUnsupported
+	}
+	if size := bulkInSize(intf); size > 0 {
+		return size, nil
+	}
+	// CDC ACM data endpoints live in a sibling (data) interface.
+	usb := filepath.Dir(intf)
+	sibs, _ := filepath.Glob(filepath.Join(usb, filepath.Base(usb)+":*"))
+	for _, sib := range sibs {
+		if size := bulkInSize(sib); size > 0 {
+			return size, nil
+		}
+	}
+	return 0, ErrUnsupported
+}
+
+// listPorts lists ttys backed by a real device in sysfs. Virtual consoles, ptys and
+// other ttys without device are skipped, as well as unused legacy 8250 ports (platform ttyS).
+func listPorts() ([]PortInfo, error) {
+	ents, err := os.ReadDir(sysTTY)
+	if err != nil {
+		return nil, err
+	}
+	ports := []PortInfo{}
+	for _, ent := range ents {
+		name := ent.Name()
+		dev, err := filepath.EvalSymlinks(filepath.Join(sysTTY, name, "device"))
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(name, "ttyS") {
+			if sub, _ := filepath.EvalSymlinks(filepath.Join(dev, "subsystem")); filepath.Base(sub) == "platform" {
+				continue
+			}
+		}
+		info := PortInfo{Name: "/dev/" + name}
+		if usb := sysfsUp(dev, "idVendor"); usb != "" {
+			vid, _ := strconv.ParseUint(sysfsRead(filepath.Join(usb, "idVendor")), 16, 16)
+			pid, _ := strconv.ParseUint(sysfsRead(filepath.Join(usb, "idProduct")), 16, 16)
+			info.VID, info.PID = uint16(vid), uint16(pid)
+			info.Manufacturer = sysfsRead(filepath.Join(usb, "manufacturer"))
+			info.Product = sysfsRead(filepath.Join(usb, "product"))
+			info.SerialNumber = sysfsRead(filepath.Join(usb, "serial"))
+		}
+		ports = append(ports, info)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Name < ports[j].Name })
+	return ports, nil
+}
+
+// watchPorts watches /dev with inotify, signaling on the returned channel when device
+// nodes are created or removed. It returns a nil channel if inotify isn't available.
+func watchPorts() (<-chan struct{}, func()) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, nil
+	}
+	mask := uint32(syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM)
+	if _, err := syscall.InotifyAddWatch(fd, "/dev", mask); err != nil {
+		syscall.Close(fd)
+		return nil, nil
+	}
+	f, err := poll.NewFile(uintptr(fd), "inotify")
+	if err != nil {
+		syscall.Close(fd)
+		return nil, nil
+	}
+	wake := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			if _, err := f.Read(buf); err != nil {
+				return // Closed
+			}
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return wake, func() { f.Close() }
+}
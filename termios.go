@@ -0,0 +1,54 @@
+package serial
+
+// Clone returns a copy of t. Termios holds no references (control characters are an array),
+// so the copy is fully independent.
+func (t *Termios) Clone() *Termios {
+	c := *t
+	return &c
+}
+
+// WithAttr applies attr, runs fn and then restores the previous attributes,
+// even if fn fails or panics (Ex. temporary settings for a firmware flash).
+// The first error of fn or the restore is returned.
+func (s *Serial) WithAttr(attr *Termios, fn func() error) (err error) {
+	var prev Termios
+	if err = s.GetAttr(&prev); err != nil {
+		return err
+	}
+	if err = s.SetAttr(attr); err != nil {
+		return err
+	}
+	defer func() {
+		if rerr := s.SetAttr(&prev); err == nil {
+			err = rerr
+		}
+	}()
+	return fn()
+}
+
+// CC returns control character idx (Ex. VSTART), 0 for an invalid index.
+func (t *Termios) CC(idx int) byte {
+	if idx < 0 || idx >= len(t.Cc) {
+		return 0
+	}
+	return byte(t.Cc[idx])
+}
+
+// SetCC sets control character idx (Ex. VSTART, VSTOP to change XON/XOFF characters).
+// Invalid indices are ignored. Apply the result with SetAttr.
+func (t *Termios) SetCC(idx int, val byte) {
+	if idx >= 0 && idx < len(t.Cc) {
+		t.Cc[idx] = val
+	}
+}
+
+// Configure reads serial attributes once, lets fn modify them and applies the result with a
+// single attribute change, so several settings (Ex. speed and parity) take effect together
+// without inconsistent intermediate states.
+func (s *Serial) Configure(fn func(t *Termios)) error {
+	defer s.dropInput()
+	return s.wrapErr("Configure", s.updateAttr(func(t *Termios) error {
+		fn(t)
+		return nil
+	}))
+}
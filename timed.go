@@ -0,0 +1,39 @@
+package serial
+
+import "time"
+
+// stamp records the receive time of bytes just read from the OS.
+func (s *Serial) stamp() {
+	t := time.Now()
+	s.bmu.Lock()
+	s.rxt = t
+	s.bmu.Unlock()
+}
+
+// rxTime returns the time bytes were last read from the OS.
+func (s *Serial) rxTime() time.Time {
+	s.bmu.Lock()
+	defer s.bmu.Unlock()
+	return s.rxt
+}
+
+// ReadTimed reads like Read, also returning the time (with monotonic clock reading) taken
+// right after the read syscall delivering the data returned.
+// Bytes already read ahead (ReadByte, Peek...) carry the time of the latest read syscall.
+func (s *Serial) ReadTimed(b []byte) (int, time.Time, error) {
+	n, err := s.Read(b)
+	if n == 0 {
+		return n, time.Time{}, err
+	}
+	return n, s.rxTime(), err
+}
+
+// ReadLineTimed reads text line like ReadLine, also returning the time the
+// read syscall delivering the line terminator returned.
+func (s *Serial) ReadLineTimed() (string, time.Time, error) {
+	line, err := s.ReadLine()
+	if err != nil {
+		return line, time.Time{}, err
+	}
+	return line, s.rxTime(), nil
+}
@@ -0,0 +1,56 @@
+package serial
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// TraceFormat selects how trace lines are time stamped.
+type TraceFormat int
+
+const (
+	TRACE_RELATIVE TraceFormat = iota // Microseconds since serial was opened
+	TRACE_ABSOLUTE                    // Wall clock time, RFC3339 with nanoseconds
+	TRACE_UNIXNANO                    // Unix time in nanoseconds
+)
+
+// SetTrace sets a writer receiving a hex dump of every read and write (nil disables tracing).
+// Each line has a time stamp (see SetTraceFormat), direction (R or W) and data bytes:
+//   1234567 R 4f 4b 0d 0a
+func (s *Serial) SetTrace(w io.Writer) {
+	s.tmu.Lock()
+	s.tw = w
+	s.tmu.Unlock()
+}
+
+// SetTraceFormat sets trace time stamps format (TRACE_RELATIVE by default).
+func (s *Serial) SetTraceFormat(f TraceFormat) {
+	s.tmu.Lock()
+	s.tfmt = f
+	s.tmu.Unlock()
+}
+
+// trace writes a trace line for data b transferred in direction dir.
+func (s *Serial) trace(dir byte, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	s.tmu.Lock()
+	defer s.tmu.Unlock()
+	if s.tw == nil {
+		return
+	}
+	now := time.Now()
+	var stamp string
+	switch s.tfmt {
+	case TRACE_ABSOLUTE:
+		stamp = now.Format(time.RFC3339Nano)
+	case TRACE_UNIXNANO:
+		stamp = strconv.FormatInt(now.UnixNano(), 10)
+	default:
+		stamp = strconv.FormatInt(int64(now.Sub(s.opened)/time.Microsecond), 10)
+	}
+	fmt.Fprintf(s.tw, "%s %c % x\n", stamp, dir, b)
+}
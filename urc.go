@@ -0,0 +1,168 @@
+package serial
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type urcRoute struct {
+	prefix string
+	ch     chan string
+}
+
+// URCReader reads lines from serial in background, routing unsolicited result codes (URCs)
+// to per-prefix channels and leaving the remaining lines for command responses.
+// While an URCReader is running, serial must not be read from elsewhere;
+// use URCReader.ATCommand instead of Serial.ATCommand.
+type URCReader struct {
+	s      *Serial
+	op     *readOp
+	mu     sync.Mutex
+	routes []urcRoute
+	lines  chan string
+	done   chan struct{}
+	once   sync.Once
+	exited chan struct{}
+	err    error
+}
+
+// NewURCReader starts reading lines from serial in background.
+// The explicit read deadline (SetReadDeadline) doesn't apply to the reader.
+func NewURCReader(s *Serial) *URCReader {
+	u := &URCReader{
+		s:      s,
+		lines:  make(chan string, 64),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	u.op = s.beginBackgroundRead()
+	go u.run()
+	return u
+}
+
+func (u *URCReader) run() {
+	defer func() {
+		u.mu.Lock()
+		for _, r := range u.routes {
+			close(r.ch)
+		}
+		u.routes = nil
+		u.mu.Unlock()
+		close(u.lines)
+		u.s.endRead(u.op)
+		close(u.exited)
+	}()
+	for {
+		line, err := u.s.ReadLine()
+		select {
+		case <-u.done:
+			return
+		default:
+		}
+		if err == ErrTimeout {
+			continue
+		}
+		if err != nil {
+			u.mu.Lock()
+			u.err = err
+			u.mu.Unlock()
+			return
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if ch := u.route(line); ch != nil {
+			select {
+			case ch <- line:
+			case <-u.done:
+				return
+			}
+			continue
+		}
+		// Lines nobody is waiting for are dropped when the buffer is full.
+		select {
+		case u.lines <- line:
+		default:
+		}
+	}
+}
+
+func (u *URCReader) route(line string) chan string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, r := range u.routes {
+		if strings.HasPrefix(line, r.prefix) {
+			return r.ch
+		}
+	}
+	return nil
+}
+
+// Register returns a channel receiving lines starting with prefix (Ex. "+CMTI:", "RING").
+// Prefixes are matched in registration order. The channel must be drained by the caller,
+// otherwise line reading is blocked. Channels are closed when the reader stops.
+func (u *URCReader) Register(prefix string) <-chan string {
+	ch := make(chan string, 16)
+	u.mu.Lock()
+	u.routes = append(u.routes, urcRoute{prefix: prefix, ch: ch})
+	u.mu.Unlock()
+	return ch
+}
+
+// ATCommand sends AT command cmd (CR terminated) and collects non URC lines as its response
+// until a final result code is received or timeout expires. See Serial.ATCommand.
+func (u *URCReader) ATCommand(cmd string, timeout time.Duration) (resp []string, err error) {
+	// Discard stale lines so they aren't taken as part of the response.
+	for stale := true; stale; {
+		select {
+		case _, ok := <-u.lines:
+			if !ok {
+				return nil, u.Err()
+			}
+		default:
+			stale = false
+		}
+	}
+	if _, err = u.s.WriteString(cmd + "\r"); err != nil {
+		return nil, err
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for {
+		select {
+		case line, ok := <-u.lines:
+			if !ok {
+				return resp, u.Err()
+			}
+			if line == cmd {
+				continue
+			}
+			if final, err := atFinal(line); final {
+				return resp, err
+			}
+			resp = append(resp, line)
+		case <-t.C:
+			return resp, ErrTimeout
+		}
+	}
+}
+
+// Err returns the error that stopped the background reader.
+func (u *URCReader) Err() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.err
+}
+
+// Close stops the background reader and waits for it to finish. Serial is not closed.
+func (u *URCReader) Close() error {
+	u.once.Do(func() { close(u.done) })
+	// Unblock pending ReadLine.
+	if err := u.s.cancelRead(u.op); err != nil {
+		return err
+	}
+	<-u.exited
+	return nil
+}
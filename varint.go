@@ -0,0 +1,41 @@
+package serial
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// VARINT_FRAME_MAX bounds frames read by ReadVarintFrame with max == 0, so a bad length
+// from the peer can't exhaust memory.
+const VARINT_FRAME_MAX = 1 << 20
+
+// ReadVarintFrame reads a frame prefixed by its length encoded as unsigned varint (LEB128).
+// Frames longer than max bytes (0 = VARINT_FRAME_MAX) return ErrFrameTooLong, leaving
+// the payload unread. On timeout the partial payload is returned with ErrTimeout.
+func (s *Serial) ReadVarintFrame(max int) ([]byte, error) {
+	if max < 0 {
+		return nil, errors.New("invalid max frame length")
+	}
+	if max == 0 {
+		max = VARINT_FRAME_MAX
+	}
+	l, err := binary.ReadUvarint(s)
+	if err != nil {
+		return nil, err
+	}
+	if l > uint64(max) {
+		return nil, ErrFrameTooLong
+	}
+	b := make([]byte, l)
+	n, err := s.readFull(b)
+	return b[:n], err
+}
+
+// WriteVarintFrame writes p prefixed by its length encoded as unsigned varint (LEB128).
+func (s *Serial) WriteVarintFrame(p []byte) error {
+	b := binary.AppendUvarint(make([]byte, 0, len(p)+binary.MaxVarintLen64), uint64(len(p)))
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	_, err := s.writeFull(append(b, p...))
+	return err
+}
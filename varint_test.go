@@ -0,0 +1,98 @@
+package serial
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestReadWriteVarintFrame(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	frames := [][]byte{{}, []byte("hi"), bytes.Repeat([]byte{0xa5}, 300)}
+	for _, f := range frames {
+		if err := a.WriteVarintFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range frames {
+		got, err := b.ReadVarintFrame(1024)
+		if err != nil || !bytes.Equal(got, f) {
+			t.Fatalf("ReadVarintFrame = % x, %v, want % x", got, err, f)
+		}
+	}
+}
+
+func TestVarintFrameLength(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	a.WriteVarintFrame(make([]byte, 300))
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(b, buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xac, 0x02}; !bytes.Equal(buf, want) {
+		t.Fatalf("300 byte length prefix = % x, want % x", buf, want)
+	}
+}
+
+func TestReadVarintFrameTooLong(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	a.WriteVarintFrame(make([]byte, 300))
+	if _, err := b.ReadVarintFrame(299); err != ErrFrameTooLong {
+		t.Fatalf("ReadVarintFrame error = %v, want ErrFrameTooLong", err)
+	}
+}
+
+func TestReadVarintFrameChunked(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	p := bytes.Repeat([]byte("0123456789"), 30)
+	raw := append([]byte{0xac, 0x02}, p...)
+	go func() {
+		for len(raw) > 7 {
+			a.Write(raw[:7])
+			raw = raw[7:]
+			time.Sleep(2 * time.Millisecond)
+		}
+		a.Write(raw)
+	}()
+	got, err := b.ReadVarintFrame(1024)
+	if err != nil || !bytes.Equal(got, p) {
+		t.Fatalf("ReadVarintFrame = %d bytes, %v, want %d bytes", len(got), err, len(p))
+	}
+}
+
+func TestReadVarintFrameTimeout(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(100 * time.Millisecond)
+	a.Write([]byte{0x05, 'a', 'b'})
+	got, err := b.ReadVarintFrame(16)
+	if err != ErrTimeout || string(got) != "ab" {
+		t.Fatalf("ReadVarintFrame = %q, %v, want \"ab\", ErrTimeout", got, err)
+	}
+}
+
+func TestReadVarintFrameMax(t *testing.T) {
+	a, b := openTestPair(t)
+	b.SetReadTimeout(time.Second)
+	if _, err := b.ReadVarintFrame(-1); err == nil || err == ErrTimeout {
+		t.Fatalf("ReadVarintFrame(-1) error = %v, want invalid max", err)
+	}
+	// Zero means unlimited up to VARINT_FRAME_MAX.
+	a.WriteVarintFrame([]byte("hello"))
+	if got, err := b.ReadVarintFrame(0); err != nil || string(got) != "hello" {
+		t.Fatalf("ReadVarintFrame(0) = %q, %v, want \"hello\"", got, err)
+	}
+	a.Write(binary.AppendUvarint(nil, VARINT_FRAME_MAX+1))
+	if _, err := b.ReadVarintFrame(0); err != ErrFrameTooLong {
+		t.Fatalf("ReadVarintFrame(0) of oversized frame error = %v, want ErrFrameTooLong", err)
+	}
+	a.Write(binary.AppendUvarint(nil, 1<<62))
+	if _, err := b.ReadVarintFrame(1 << 30); err != ErrFrameTooLong {
+		t.Fatalf("ReadVarintFrame of huge length error = %v, want ErrFrameTooLong", err)
+	}
+}